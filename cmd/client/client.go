@@ -22,10 +22,39 @@ import (
 var (
 	loops = flag.Int("loops", 10, "loops")
 
-	clientmode  = flag.String("clientmode", "Modulus", "clientmode 'modulus/mod/m' or 'percent/per/p'")
-	clientvalue = flag.Int("clientvalue", 2, "clientvalue integers only, modulus 1-10000, percent 1-100")
-	servermode  = flag.String("servermode", "Modulus", "servermode 'modulus/mod/m' or 'percent/per/p'")
-	servervalue = flag.Int("servervalue", 2, "servervalue integers only, modulus 1-10000, percent 1-100")
+	clientmode   = flag.String("clientmode", "Modulus", "clientmode 'modulus/mod/m', 'percent/per/p', 'ppm', 'first/f', 'sequence/seq/s', 'markov/mk' or 'ramp/r'")
+	clientvalue  = flag.Int("clientvalue", 2, "clientvalue integers only, modulus 1-10000, percent 1-100, ppm 1-1000000, first 1-1000000")
+	clientoffset = flag.Int("clientoffset", 0, "clientoffset is the request counter modulus or first starts at, 0-1000000")
+	servermode   = flag.String("servermode", "Modulus", "servermode 'modulus/mod/m', 'percent/per/p', 'ppm', 'first/f', 'sequence/seq/s', 'markov/mk' or 'ramp/r'")
+	servervalue  = flag.Int("servervalue", 2, "servervalue integers only, modulus 1-10000, percent 1-100, ppm 1-1000000, first 1-1000000")
+	serveroffset = flag.Int("serveroffset", 0, "serveroffset is the request counter modulus or first starts at, 0-1000000")
+
+	clientsequence = flag.String("clientsequence", "", "clientsequence for clientmode sequence. e.g. 'ok,14,14,ok,4,ok'")
+	clientrepeat   = flag.Bool("clientrepeat", false, "clientrepeat restarts the clientsequence when it is finished")
+	serversequence = flag.String("serversequence", "", "serversequence for servermode sequence. e.g. 'ok,14,14,ok,4,ok'")
+	serverrepeat   = flag.Bool("serverrepeat", false, "serverrepeat restarts the serversequence when it is finished")
+	clientmarkov   = flag.String("clientmarkov", "", "clientmarkov for clientmode markov, ppm 'good to bad,bad to good,good rate,bad rate'. e.g. '10000,200000,0,900000'")
+	clientramp     = flag.String("clientramp", "", "clientramp for clientmode ramp, 'from%,to%,duration[,linear|stepN[,hold|fall]]'. e.g. '0,50,5m'")
+	serverramp     = flag.String("serverramp", "", "serverramp for servermode ramp, 'from%,to%,duration[,linear|stepN[,hold|fall]]'. e.g. '0,50,5m'")
+	servermarkov   = flag.String("servermarkov", "", "servermarkov for servermode markov, ppm 'good to bad,bad to good,good rate,bad rate'. e.g. '10000,200000,0,900000'")
+	session        = flag.String("session", "", "session id, so the server keeps a seperate sequence for this client")
+	scope          = flag.String("scope", "", "server counter scope 'global', 'method', 'peer', 'identity' or 'session'")
+
+	target = flag.String("target", "", "only fault the downstream service with this name, sent in 'faulttarget'")
+	hops   = flag.Int("hops", 0, "fault this many service hops downstream, sent in 'faulthops'. 0-32")
+
+	action     = flag.String("action", "server", "where the fault is injected, 'server' with the fault headers, 'error' in the client, 'delay' in the client, or 'mutate' the request")
+	mutate     = flag.String("mutate", "", "mutate the request, for the action 'mutate'. e.g. drop:id,unknown,oversize:name,negative")
+	localDelay = flag.Duration("localDelay", 0, "delay in the client, before the action 'error' or 'delay'. e.g. 100ms")
+
+	connection = flag.String("connection", "", "ask the server to 'close', 'reset', or 'goaway' the connection, sent in 'faultconnection'")
+	panicValue = flag.String("panic", "", "ask the server to panic with this value, sent in 'faultpanic'")
+	cancel     = flag.String("cancel", "", "ask the server to cancel the handler context after this duration, sent in 'faultcancel'. e.g. 0s or 50ms")
+	corrupt    = flag.String("corrupt", "", "ask the server to corrupt the response, sent in 'faultcorrupt'. e.g. zero,truncate:message")
+
+	faultSecret = flag.String("faultSecret", "", "sign the fault headers with this HMAC secret, for a server with -faultSecret")
+
+	dryRun = flag.Bool("dryRun", false, "dry run, log and count the faults, but don't inject them")
 
 	codes = flag.String("codes", "10,12,14", "GRPC status codes to return. comma seperated")
 
@@ -50,32 +79,64 @@ func main() {
 
 	conf := unaryClientFaultInjector.UnaryClientInterceptorConfig{
 		Client: unaryClientFaultInjector.ModeValue{
-			Mode:  unaryClientFaultInjector.StringToMode(*clientmode),
-			Value: *clientvalue,
+			Mode:     unaryClientFaultInjector.StringToMode(*clientmode),
+			Value:    *clientvalue,
+			Offset:   *clientoffset,
+			Sequence: *clientsequence,
+			Repeat:   *clientrepeat,
+			Markov:   *clientmarkov,
+			Ramp:     *clientramp,
 		},
 		Server: unaryClientFaultInjector.ModeValue{
-			Mode:  unaryClientFaultInjector.StringToMode(*servermode),
-			Value: *servervalue,
+			Mode:     unaryClientFaultInjector.StringToMode(*servermode),
+			Value:    *servervalue,
+			Offset:   *serveroffset,
+			Sequence: *serversequence,
+			Repeat:   *serverrepeat,
+			Markov:   *servermarkov,
+			Ramp:     *serverramp,
 		},
-		Codes: *codes,
+		Codes:   *codes,
+		Session: *session,
+		Scope:   *scope,
+		Secret:  []byte(*faultSecret),
+		Target:  *target,
+		Hops:    *hops,
+
+		Connection: *connection,
+		Corrupt:    *corrupt,
+		Panic:      *panicValue,
+		Cancel:     *cancel,
+		Mutate:     *mutate,
+		Action:     unaryClientFaultInjector.StringToAction(*action),
+		LocalDelay: *localDelay,
 	}
 
+	// loadScenario is in client_fault.go, and client_nofault.go for the "nofaultinjection" build tag
+	loadScenario(&conf)
+
 	if err := unaryClientFaultInjector.CheckConfig(conf); err != nil {
 		log.Fatal(err)
 	}
 
+	if *dryRun {
+		unaryClientFaultInjector.SetDryRun(true)
+	}
+
 	// Set up a connection to the server with service config and create the channel.
 	// However, the recommended approach is to fetch the retry configuration
 	// (which is part of the service config) from the name resolver rather than
 	// defining it on the client side.
-	conn, err := grpc.NewClient(
-		*addr,
+	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithDefaultServiceConfig(string(servicePolicyBytes)),
 		grpc.WithUnaryInterceptor(
 			unaryClientFaultInjector.UnaryClientFaultInjector(conf, *debugLevel),
 		),
-	)
+	}
+	opts = append(opts, dialOptions()...)
+
+	conn, err := grpc.NewClient(*addr, opts...)
 
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
@@ -112,4 +173,5 @@ func main() {
 	}
 
 	log.Printf("Complete.  success:%d fault:%d", success, fault)
+	log.Printf("Stats: %+v", unaryClientFaultInjector.GetStats())
 }
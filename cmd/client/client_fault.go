@@ -0,0 +1,54 @@
+//go:build !nofaultinjection
+
+package main
+
+// This .go file holds the scenario, and the faultnet dialer wiring, which are not built
+// with the "nofaultinjection" build tag
+
+import (
+	"flag"
+	"log"
+
+	"google.golang.org/grpc"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/faultnet"
+	"github.com/randomizedcoder/grpcFaultInjection/unaryClientFaultInjector"
+)
+
+var (
+	scenario = flag.String("scenario", "", "filename of a fault scenario .json or .yaml. e.g. fault_scenario.json")
+
+	netLatency    = flag.Duration("netLatency", 0, "network latency added before each write. e.g. 50ms")
+	netBandwidth  = flag.Int64("netBandwidth", 0, "network bandwidth limit, bytes per second. 0 is unlimited")
+	netAfterBytes = flag.Int64("netAfterBytes", 0, "close the connection after this many bytes. 0 is disabled")
+	netAfter      = flag.Duration("netAfter", 0, "close the connection after this duration. 0 is disabled")
+	netReset      = flag.Bool("netReset", false, "close the connection with a TCP RST, for netAfterBytes and netAfter")
+)
+
+// loadScenario loads the -scenario into the config
+func loadScenario(conf *unaryClientFaultInjector.UnaryClientInterceptorConfig) {
+	if *scenario == "" {
+		return
+	}
+	sc, err := faultScenario.Load(*scenario)
+	if err != nil {
+		log.Fatal(err)
+	}
+	conf.Scenario = sc
+}
+
+// dialOptions returns the faultnet dialer, for the -net flags
+func dialOptions() []grpc.DialOption {
+	netConf := faultnet.Config{
+		Latency:    *netLatency,
+		Bandwidth:  *netBandwidth,
+		AfterBytes: *netAfterBytes,
+		After:      *netAfter,
+		Reset:      *netReset,
+	}
+	if err := netConf.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	return []grpc.DialOption{grpc.WithContextDialer(faultnet.Dialer(netConf))}
+}
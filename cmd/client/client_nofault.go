@@ -0,0 +1,20 @@
+//go:build nofaultinjection
+
+package main
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// There is no scenario, or faultnet dialer
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/randomizedcoder/grpcFaultInjection/unaryClientFaultInjector"
+)
+
+// loadScenario does nothing, fault injection is not compiled in
+func loadScenario(conf *unaryClientFaultInjector.UnaryClientInterceptorConfig) {}
+
+// dialOptions returns no options, so the default dialer is used
+func dialOptions() []grpc.DialOption {
+	return nil
+}
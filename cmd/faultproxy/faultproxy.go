@@ -0,0 +1,133 @@
+//go:build !nofaultinjection
+
+package main
+
+// faultproxy is a transparent gRPC proxy, which injects faults between any client and server
+// Any method is forwarded to the upstream as raw bytes, so no protos are needed, and services
+// which aren't written in Go can be chaos tested
+// The fault headers and scenario rules are the same as the unaryServerFaultInjector
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
+)
+
+func main() {
+	port := flag.Int("port", 50053, "port number")
+	upstream := flag.String("upstream", "localhost:50052", "the upstream address to forward to")
+	debugLevel := flag.Int("debugLevel", 11, "debugLevel.  > 10 for output")
+	scope := flag.String("scope", "global", "default counter scope 'global', 'method', 'peer', 'identity' or 'session'")
+	maxCounters := flag.Int("maxCounters", 10000, "maximum number of scoped counters")
+	counterTTL := flag.Duration("counterTTL", 10*time.Minute, "scoped counters unused for the TTL are reset")
+	scenario := flag.String("scenario", "", "filename of a fault scenario .json or .yaml. e.g. fault_scenario.yaml")
+	scenarioReload := flag.Duration("scenarioReload", 0, "poll the scenario file for changes at this interval. e.g. 5s. 0 disables reload")
+	maxFaults := flag.Uint64("maxFaults", 0, "fault budget, maximum total faults. 0 is unlimited")
+	faultsPerSecond := flag.Float64("faultsPerSecond", 0, "fault budget, maximum faults per second. 0 is unlimited")
+	trustPeers := flag.String("trustPeers", "", "only trust fault headers from these CIDRs, comma seperated. e.g. '10.0.0.0/8,127.0.0.1/32'")
+	faultSecret := flag.String("faultSecret", "", "only trust fault headers signed with this HMAC secret")
+	forwardFaultHeaders := flag.Bool("forwardFaultHeaders", false, "forward the fault headers to the upstream, which are removed by default")
+	dryRun := flag.Bool("dryRun", false, "dry run, log and count the faults, but don't inject them")
+
+	flag.Parse()
+
+	conf := unaryServerFaultInjector.UnaryServerInterceptorConfig{
+		Scope:       unaryServerFaultInjector.StringToScope(*scope),
+		MaxCounters: *maxCounters,
+		CounterTTL:  *counterTTL,
+		Budget: unaryServerFaultInjector.Budget{
+			MaxFaults:       *maxFaults,
+			FaultsPerSecond: *faultsPerSecond,
+		},
+		Trust: unaryServerFaultInjector.Trust{
+			Secret: []byte(*faultSecret),
+		},
+	}
+
+	if *trustPeers != "" {
+		for _, p := range strings.Split(*trustPeers, ",") {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(p))
+			if err != nil {
+				log.Fatalf("invalid trustPeers: %v", err)
+			}
+			conf.Trust.Peers = append(conf.Trust.Peers, prefix)
+		}
+	}
+
+	switch {
+	case *scenario != "" && *scenarioReload > 0:
+		w, err := faultScenario.NewWatcher(*scenario, *scenarioReload)
+		if err != nil {
+			log.Fatalf("failed to load scenario: %v", err)
+		}
+		if err := w.SetCheck(checkScenario); err != nil {
+			log.Fatalf("invalid scenario: %v", err)
+		}
+		go w.Run(context.Background())
+		conf.ScenarioWatcher = w
+	case *scenario != "":
+		sc, err := faultScenario.Load(*scenario)
+		if err != nil {
+			log.Fatalf("failed to load scenario: %v", err)
+		}
+		if err := checkScenario(sc); err != nil {
+			log.Fatalf("invalid scenario: %v", err)
+		}
+		conf.Scenario = sc
+	}
+
+	if *dryRun {
+		unaryServerFaultInjector.SetDryRun(true)
+	}
+
+	// kill -USR1 toggles fault injection, and kill -USR2 toggles dry run
+	go unaryServerFaultInjector.HandleSignals(context.Background())
+
+	cc, err := grpc.NewClient(*upstream,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		log.Fatalf("failed to create upstream client: %v", err)
+	}
+	defer cc.Close()
+
+	p := &proxy{
+		upstream:            cc,
+		interceptor:         unaryServerFaultInjector.UnaryServerFaultInjectorWithConfig(conf, *debugLevel),
+		forwardFaultHeaders: *forwardFaultHeaders,
+	}
+
+	address := fmt.Sprintf(":%v", *port)
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	fmt.Println("listen on address", address, "upstream", *upstream)
+
+	s := newServer(p)
+
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
+
+// newServer returns a grpc server, which forwards every method through the proxy
+func newServer(p *proxy) *grpc.Server {
+	return grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(p.handler),
+	)
+}
@@ -0,0 +1,261 @@
+//go:build !nofaultinjection
+
+package main
+
+// This .go file holds the transparent proxy, which forwards any gRPC method to the upstream
+// The messages are forwarded as raw bytes, so no protos are needed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
+)
+
+const (
+	faultcodesHeader   = "faultcodes"
+	faultcorruptHeader = "faultcorrupt"
+)
+
+var (
+	errMethod = status.Error(codes.Internal, "faultproxy no method in stream")
+
+	errCorrupt = errors.New("corrupt is not supported by the faultproxy, which forwards raw bytes")
+	errEmpty   = errors.New("code 0 ( empty response ) is not supported by the faultproxy, which forwards raw bytes")
+)
+
+// frame is a raw gRPC message, which is never decoded
+type frame struct {
+	payload []byte
+}
+
+// rawCodec passes the message bytes through unchanged
+// The name is "proto", so the content-type is unchanged
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "rawCodec can't marshal %T", v)
+	}
+	return f.payload, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return status.Errorf(codes.Internal, "rawCodec can't unmarshal %T", v)
+	}
+	// the data buffer is reused by grpc, so it's copied
+	f.payload = append(f.payload[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// proxy forwards every method to the upstream, through the fault injector
+type proxy struct {
+	upstream            *grpc.ClientConn
+	interceptor         grpc.UnaryServerInterceptor
+	forwardFaultHeaders bool
+}
+
+// handler is the grpc.UnknownServiceHandler
+// The unary server interceptor decides if the call is faulted, delayed, or forwarded,
+// so the proxy supports the same fault headers and scenario rules as the server
+// Streaming calls are faulted, or not, when the stream starts
+// A "faultpanic" is recovered, and returned as an Internal error, so the proxy isn't killed
+func (p *proxy) handler(_ any, stream grpc.ServerStream) (err error) {
+
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return errMethod
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     p,
+		FullMethod: method,
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if err := checkHeaders(md); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("recovery method:%s panic:%v", method, r)
+			err = status.Errorf(codes.Internal, "panic: %v", r)
+		}
+	}()
+
+	_, err = p.interceptor(stream.Context(), nil, info,
+		func(ctx context.Context, _ any) (any, error) {
+			return nil, p.forward(ctx, method, stream)
+		})
+
+	return err
+}
+
+// forward copies the messages in both directions, until the upstream finishes
+func (p *proxy) forward(ctx context.Context, method string, stream grpc.ServerStream) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, p.outgoingMD(md))
+
+	desc := &grpc.StreamDesc{
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+
+	up, err := p.upstream.NewStream(ctx, desc, method, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+
+	// client to upstream
+	sent := make(chan error, 1)
+	go func() {
+		sent <- toUpstream(stream, up)
+	}()
+
+	// upstream to client
+	errR := toClient(up, stream)
+
+	stream.SetTrailer(up.Trailer())
+
+	if errR != nil {
+		return errR
+	}
+
+	if err := <-sent; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// toUpstream copies the client messages to the upstream, and closes the send on EOF
+func toUpstream(stream grpc.ServerStream, up grpc.ClientStream) error {
+	for {
+		f := &frame{}
+		if err := stream.RecvMsg(f); err != nil {
+			if errors.Is(err, io.EOF) {
+				return up.CloseSend()
+			}
+			return err
+		}
+		if err := up.SendMsg(f); err != nil {
+			// the upstream error is returned by RecvMsg
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// toClient copies the upstream response headers and messages to the client
+func toClient(up grpc.ClientStream, stream grpc.ServerStream) error {
+
+	header, err := up.Header()
+	if err != nil {
+		return err
+	}
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+
+	for {
+		f := &frame{}
+		if err := up.RecvMsg(f); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := stream.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+// outgoingMD copies the request headers, except the pseudo headers, and content-type,
+// which are set by grpc.  The fault headers are applied by the proxy, so they are
+// only forwarded if forwardFaultHeaders is set
+func (p *proxy) outgoingMD(md metadata.MD) metadata.MD {
+
+	out := make(metadata.MD, len(md))
+	for k, v := range md {
+		switch {
+		case strings.HasPrefix(k, ":"), k == "content-type", k == "user-agent":
+			continue
+		case signature.IsFaultHeader(k) && !p.forwardFaultHeaders:
+			continue
+		}
+		out[k] = v
+	}
+
+	return out
+}
+
+// checkScenario rejects the rule actions which need the protos, so the proxy can't apply them
+// The corrupt action, and the code 0 empty response, would otherwise be silently ignored
+func checkScenario(s *faultScenario.Scenario) error {
+
+	for i, r := range s.Rules {
+
+		if r.Action.Corrupt != "" {
+			return fmt.Errorf("rule %d %q: %w", i, r.Name, errCorrupt)
+		}
+
+		if hasEmptyCode(r.Action.Codes) {
+			return fmt.Errorf("rule %d %q: %w", i, r.Name, errEmpty)
+		}
+	}
+
+	return nil
+}
+
+// checkHeaders rejects the fault headers which need the protos, like checkScenario
+// Otherwise the upstream response is forwarded, but counted as faulted
+func checkHeaders(md metadata.MD) error {
+
+	if len(md.Get(faultcorruptHeader)) > 0 {
+		return errCorrupt
+	}
+
+	for _, c := range md.Get(faultcodesHeader) {
+		if hasEmptyCode(c) {
+			return errEmpty
+		}
+	}
+
+	return nil
+}
+
+// hasEmptyCode returns true if the comma seperated codes include the code 0
+func hasEmptyCode(list string) bool {
+	for _, c := range strings.Split(list, ",") {
+		if code, err := strconv.ParseInt(strings.TrimSpace(c), 0, 64); err == nil && code == 0 {
+			return true
+		}
+	}
+	return false
+}
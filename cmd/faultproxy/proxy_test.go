@@ -0,0 +1,209 @@
+//go:build !nofaultinjection
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/examples/features/proto/echo"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
+)
+
+type echoServer struct {
+	echo.UnimplementedEchoServer
+}
+
+func (echoServer) UnaryEcho(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("faultmodulus")) > 0 {
+		return nil, status.Error(codes.FailedPrecondition, "fault header forwarded")
+	}
+	return &echo.EchoResponse{Message: req.Message}, nil
+}
+
+func (echoServer) ServerStreamingEcho(req *echo.EchoRequest, stream echo.Echo_ServerStreamingEchoServer) error {
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&echo.EchoResponse{Message: req.Message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type proxyTest struct {
+	name string
+	md   metadata.MD
+	code codes.Code
+}
+
+// go test -run TestProxy -v
+func TestProxy(t *testing.T) {
+	tests := []proxyTest{
+		{
+			name: "forwarded",
+			md:   metadata.Pairs("x-other", "1"),
+			code: codes.OK,
+		},
+		{
+			name: "fault, not forwarded",
+			md:   metadata.Pairs("faultmodulus", "1", "faultcodes", "14"),
+			code: codes.Unavailable,
+		},
+		{
+			name: "panic, recovered",
+			md:   metadata.Pairs("faultmodulus", "1", "faultpanic", "boom"),
+			code: codes.Internal,
+		},
+		{
+			name: "forwarded after the panic",
+			md:   metadata.Pairs("x-other", "1"),
+			code: codes.OK,
+		},
+		{
+			name: "code 0 header, rejected",
+			md:   metadata.Pairs("faultmodulus", "1", "faultcodes", "14,0"),
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "corrupt header, rejected",
+			md:   metadata.Pairs("faultmodulus", "1", "faultcodes", "14", "faultcorrupt", "zero"),
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "no fault, fault headers removed",
+			md:   metadata.Pairs("faultmodulus", "2", "faultoffset", "2", "faultcodes", "14"),
+			code: codes.OK,
+		},
+	}
+
+	client := startProxy(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ctx = metadata.NewOutgoingContext(ctx, tt.md)
+
+			resp, err := client.UnaryEcho(ctx, &echo.EchoRequest{Message: tt.name})
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("test: %s, code:%s != tt.code:%s, err:%v", tt.name, code, tt.code, err)
+			}
+			if err == nil && resp.Message != tt.name {
+				t.Errorf("test: %s, message:%q != %q", tt.name, resp.Message, tt.name)
+			}
+		})
+	}
+
+	t.Run("server streaming", func(t *testing.T) {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.ServerStreamingEcho(ctx, &echo.EchoRequest{Message: "stream"})
+		if err != nil {
+			t.Fatalf("ServerStreamingEcho error:%v", err)
+		}
+
+		var n int
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv error:%v", err)
+			}
+			if resp.Message != "stream" {
+				t.Errorf("message:%q != stream", resp.Message)
+			}
+			n++
+		}
+		if n != 3 {
+			t.Errorf("messages:%d != 3", n)
+		}
+	})
+}
+
+// startProxy starts the upstream echo server and the proxy, and returns a client of the proxy
+func startProxy(t *testing.T) echo.EchoClient {
+
+	upLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	up := grpc.NewServer()
+	echo.RegisterEchoServer(up, echoServer{})
+	go up.Serve(upLis)
+	t.Cleanup(up.Stop)
+
+	cc, err := grpc.NewClient(upLis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+
+	p := &proxy{
+		upstream:    cc,
+		interceptor: unaryServerFaultInjector.UnaryServerFaultInjectorWithConfig(unaryServerFaultInjector.UnaryServerInterceptorConfig{}, 0),
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(p)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	client, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return echo.NewEchoClient(client)
+}
+
+type checkScenarioTest struct {
+	name      string
+	action    faultScenario.Action
+	expectErr bool
+}
+
+// go test -run TestCheckScenario -v
+func TestCheckScenario(t *testing.T) {
+	tests := []checkScenarioTest{
+		{name: "code 14", action: faultScenario.Action{Codes: "14"}, expectErr: false},
+		{name: "delay", action: faultScenario.Action{Delay: faultScenario.Duration(time.Second)}, expectErr: false},
+		{name: "corrupt", action: faultScenario.Action{Codes: "14", Corrupt: "truncate"}, expectErr: true},
+		{name: "code 0", action: faultScenario.Action{Codes: "0"}, expectErr: true},
+		{name: "codes 14 and 0", action: faultScenario.Action{Codes: "14, 0"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &faultScenario.Scenario{Rules: []faultScenario.Rule{{Name: tt.name, Action: tt.action}}}
+			if err := s.Validate(); err != nil {
+				t.Fatalf("test: %s, Validate error:%v", tt.name, err)
+			}
+			if err := checkScenario(s); (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -10,10 +10,14 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
+	"net/netip"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
 
@@ -44,29 +48,70 @@ func main() {
 
 	port := flag.Int("port", 50052, "port number")
 	debugLevel := flag.Int("debugLevel", 11, "debugLevel.  > 10 for output")
+	scope := flag.String("scope", "global", "default counter scope 'global', 'method', 'peer', 'identity' or 'session'")
+	maxCounters := flag.Int("maxCounters", 10000, "maximum number of scoped counters")
+	counterTTL := flag.Duration("counterTTL", 10*time.Minute, "scoped counters unused for the TTL are reset")
+	maxFaults := flag.Uint64("maxFaults", 0, "fault budget, maximum total faults. 0 is unlimited")
+	faultsPerSecond := flag.Float64("faultsPerSecond", 0, "fault budget, maximum faults per second. 0 is unlimited")
+	faultsBurst := flag.Int("faultsBurst", 0, "fault budget, faultsPerSecond burst. 0 is faultsPerSecond")
+	maxFaultsPerCaller := flag.Uint64("maxFaultsPerCaller", 0, "fault budget, maximum total faults per caller. 0 is unlimited")
+	dryRun := flag.Bool("dryRun", false, "dry run, log and count the faults, but don't inject them")
+	trustPeers := flag.String("trustPeers", "", "only trust fault headers from these CIDRs, comma seperated. e.g. '10.0.0.0/8,127.0.0.1/32'")
+	faultSecret := flag.String("faultSecret", "", "only trust fault headers signed with this HMAC secret")
+	trustReject := flag.Bool("trustReject", false, "reject untrusted fault headers with PermissionDenied, rather than ignoring them")
+	service := flag.String("service", "", "service name matched by the 'faulttarget' header, in addition to the gRPC service name")
+	cancelGrace := flag.Duration("cancelGrace", 100*time.Millisecond, "time for a handler to return after the 'faultcancel' cancel, before it is reported as ignoring the cancel")
 
 	flag.Parse()
 
 	address := fmt.Sprintf(":%v", *port)
 
-	lis, err := net.Listen("tcp", address)
+	conf := unaryServerFaultInjector.UnaryServerInterceptorConfig{
+		Scope:       unaryServerFaultInjector.StringToScope(*scope),
+		MaxCounters: *maxCounters,
+		CounterTTL:  *counterTTL,
+		Budget: unaryServerFaultInjector.Budget{
+			MaxFaults:          *maxFaults,
+			FaultsPerSecond:    *faultsPerSecond,
+			Burst:              *faultsBurst,
+			MaxFaultsPerCaller: *maxFaultsPerCaller,
+		},
+		Service:     *service,
+		CancelGrace: *cancelGrace,
+		Trust: unaryServerFaultInjector.Trust{
+			Secret: []byte(*faultSecret),
+			Reject: *trustReject,
+		},
+	}
 
-	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+	if *trustPeers != "" {
+		for _, p := range strings.Split(*trustPeers, ",") {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(p))
+			if err != nil {
+				log.Fatalf("invalid trustPeers: %v", err)
+			}
+			conf.Trust.Peers = append(conf.Trust.Peers, prefix)
+		}
 	}
-	fmt.Println("listen on address", address)
 
-	s := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			unaryServerFaultInjector.UnaryServerFaultInjector(*debugLevel),
-		),
-	)
+	if *dryRun {
+		unaryServerFaultInjector.SetDryRun(true)
+	}
 
-	srv := newEchoServer()
+	// kill -USR1 toggles fault injection, and kill -USR2 toggles dry run
+	go unaryServerFaultInjector.HandleSignals(context.Background())
 
-	echo.RegisterEchoServer(s, srv)
+	// serve is in server_fault.go, and server_nofault.go for the "nofaultinjection" build tag
+	serve(address, conf, *debugLevel)
+}
 
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
-	}
+// recovery returns a panic as an Internal error, so the process isn't killed
+func recovery(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("recovery method:%s panic:%v", info.FullMethod, r)
+			err = status.Errorf(codes.Internal, "panic: %v", r)
+		}
+	}()
+	return handler(ctx, req)
 }
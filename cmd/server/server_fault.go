@@ -0,0 +1,96 @@
+//go:build !nofaultinjection
+
+package main
+
+// This .go file holds the scenario, and the faultnet listener wiring, which are not built
+// with the "nofaultinjection" build tag
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/faultnet"
+	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
+
+	"google.golang.org/grpc/examples/features/proto/echo"
+)
+
+var (
+	scenario       = flag.String("scenario", "", "filename of a fault scenario .json or .yaml. e.g. fault_scenario.yaml")
+	scenarioReload = flag.Duration("scenarioReload", 0, "poll the scenario file for changes at this interval. e.g. 5s. 0 disables reload")
+
+	netLatency    = flag.Duration("netLatency", 0, "network latency added before each write. e.g. 50ms")
+	netBandwidth  = flag.Int64("netBandwidth", 0, "network bandwidth limit, bytes per second. 0 is unlimited")
+	netAfterBytes = flag.Int64("netAfterBytes", 0, "close each connection after this many bytes. 0 is disabled")
+	netAfter      = flag.Duration("netAfter", 0, "close each connection after this duration. 0 is disabled")
+	netReset      = flag.Bool("netReset", false, "close the connections with a TCP RST, for netAfterBytes and netAfter")
+	netAcceptFail = flag.Int("netAcceptFail", 0, "percent of accepted connections reset immediately. 0-100")
+	drainEvery    = flag.Duration("drainEvery", 0, "GOAWAY and gracefully drain the connections at this interval. e.g. 1m. 0 is disabled")
+)
+
+// serve loads the scenario, and serves the echo server on a faultnet listener
+func serve(address string, conf unaryServerFaultInjector.UnaryServerInterceptorConfig, debugLevel int) {
+
+	lis, err := faultnet.Listen("tcp", address, faultnet.Config{
+		Latency:           *netLatency,
+		Bandwidth:         *netBandwidth,
+		AfterBytes:        *netAfterBytes,
+		After:             *netAfter,
+		Reset:             *netReset,
+		AcceptFailPercent: *netAcceptFail,
+	})
+
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	fmt.Println("listen on address", address)
+
+	switch {
+	case *scenario != "" && *scenarioReload > 0:
+		w, err := faultScenario.NewWatcher(*scenario, *scenarioReload)
+		if err != nil {
+			log.Fatalf("failed to load scenario: %v", err)
+		}
+		go w.Run(context.Background())
+		conf.ScenarioWatcher = w
+	case *scenario != "":
+		sc, err := faultScenario.Load(*scenario)
+		if err != nil {
+			log.Fatalf("failed to load scenario: %v", err)
+		}
+		conf.Scenario = sc
+	}
+
+	// the faultnet Server can close the connections, or drain, for the "faultconnection" header
+	// a drain replaces the grpc.Server, so the interceptor is shared by every grpc.Server
+	var interceptor grpc.UnaryServerInterceptor
+
+	fs := faultnet.NewServer(lis, func() *grpc.Server {
+		// the fault injector is chained inside the recovery, so a "faultpanic" is recovered
+		s := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(recovery, interceptor),
+		)
+
+		srv := newEchoServer()
+
+		echo.RegisterEchoServer(s, srv)
+
+		return s
+	})
+
+	conf.Connections = fs
+	interceptor = unaryServerFaultInjector.UnaryServerFaultInjectorWithConfig(conf, debugLevel)
+
+	if *drainEvery > 0 {
+		go fs.Schedule(context.Background(), *drainEvery, fs.Drain)
+	}
+
+	if err := fs.Serve(); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build nofaultinjection
+
+package main
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// There is no scenario, or faultnet listener, and the interceptor passes every request
+// to the handler
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
+
+	"google.golang.org/grpc/examples/features/proto/echo"
+)
+
+// serve serves the echo server on a plain listener
+func serve(address string, conf unaryServerFaultInjector.UnaryServerInterceptorConfig, debugLevel int) {
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	fmt.Println("listen on address", address)
+
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recovery,
+			unaryServerFaultInjector.UnaryServerFaultInjectorWithConfig(conf, debugLevel)),
+	)
+
+	echo.RegisterEchoServer(s, newEchoServer())
+
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !nofaultinjection
+
 package main
 
 import (
@@ -11,6 +13,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
 	"github.com/randomizedcoder/grpcFaultInjection/unaryClientFaultInjector"
 	"github.com/randomizedcoder/grpcFaultInjection/unaryServerFaultInjector"
 
@@ -312,6 +315,286 @@ func TestComprehensive(t *testing.T) {
 			checkMaxFault:   true,
 			maxFault:        int(100 * 0.17), // target is ~16.666%
 		},
+		{
+			name: "1/1 client, 1000000 ppm server fault, loops 100, 100%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.PPM,
+					Value: 1000000,
+				},
+				Codes: "10",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      0,
+			checkMaxSuccess: true,
+			maxSuccess:      0,
+			checkMinFault:   true,
+			minFault:        100,
+			checkMaxFault:   true,
+			maxFault:        100,
+		},
+		{
+			name: "first 3 client, 1/1 server fault, loops 100, first 3 fault",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.First,
+					Value: 3,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      97,
+			checkMaxSuccess: true,
+			maxSuccess:      97,
+			checkMinFault:   true,
+			minFault:        3,
+			checkMaxFault:   true,
+			maxFault:        3,
+		},
+		{
+			name: "1/2 offset 1 client, 1/1 server fault, loops 100, = 50%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:   unaryClientFaultInjector.Modulus,
+					Value:  2,
+					Offset: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      50,
+			checkMaxSuccess: true,
+			maxSuccess:      50,
+			checkMinFault:   true,
+			minFault:        50,
+			checkMaxFault:   true,
+			maxFault:        50,
+		},
+		{
+			name: "1/1 client, server sequence ok,10,10,ok, loops 100, 2 faults",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:     unaryClientFaultInjector.Sequence,
+					Sequence: "ok,10,10,ok",
+				},
+				Session: "test_test_server_sequence",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      98,
+			checkMaxSuccess: true,
+			maxSuccess:      98,
+			checkMinFault:   true,
+			minFault:        2,
+			checkMaxFault:   true,
+			maxFault:        2,
+		},
+		{
+			name: "client sequence 10,ok repeat, 1/1 server, loops 100, = 50%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:     unaryClientFaultInjector.Sequence,
+					Sequence: "10,ok",
+					Repeat:   true,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      50,
+			checkMaxSuccess: true,
+			maxSuccess:      50,
+			checkMinFault:   true,
+			minFault:        50,
+			checkMaxFault:   true,
+			maxFault:        50,
+		},
+		{
+			name: "1/1 client, 1/2 server fault, session scope, loops 100, = 50%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:   unaryClientFaultInjector.Modulus,
+					Value:  2,
+					Offset: 1,
+				},
+				Codes:   "10",
+				Session: "test_test_session_scope",
+				Scope:   "session",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      50,
+			checkMaxSuccess: true,
+			maxSuccess:      50,
+			checkMinFault:   true,
+			minFault:        50,
+			checkMaxFault:   true,
+			maxFault:        50,
+		},
+		{
+			name: "1/1 client, first 1 server fault, session scope, loops 100, 1 fault",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.First,
+					Value: 1,
+				},
+				Codes:   "10",
+				Session: "test_test_session_first",
+				Scope:   "session",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      99,
+			checkMaxSuccess: true,
+			maxSuccess:      99,
+			checkMinFault:   true,
+			minFault:        1,
+			checkMaxFault:   true,
+			maxFault:        1,
+		},
+		{
+			name: "1/1 client, server markov always switch state, session scope, loops 100, = 50%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:   unaryClientFaultInjector.Markov,
+					Markov: "1000000,1000000,0,1000000",
+				},
+				Codes:   "10",
+				Session: "test_test_markov",
+				Scope:   "session",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      50,
+			checkMaxSuccess: true,
+			maxSuccess:      50,
+			checkMinFault:   true,
+			minFault:        50,
+			checkMaxFault:   true,
+			maxFault:        50,
+		},
+		{
+			name: "1/1 client, server ramp 100% to 100%, loops 100, = 100%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode: unaryClientFaultInjector.Ramp,
+					Ramp: "100,100,1m",
+				},
+				Codes: "10",
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      0,
+			checkMaxSuccess: true,
+			maxSuccess:      0,
+			checkMinFault:   true,
+			minFault:        100,
+			checkMaxFault:   true,
+			maxFault:        100,
+		},
+		{
+			name: "scenario sequence 10,ok, loops 100, = 50%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Scenario: &faultScenario.Scenario{
+					Rules: []faultScenario.Rule{
+						{
+							Name:   "echo",
+							Match:  faultScenario.Match{Method: "/grpc.examples.echo.Echo/*"},
+							Select: faultScenario.Select{Mode: "sequence", Sequence: "10,ok", Repeat: true},
+						},
+					},
+				},
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      50,
+			checkMaxSuccess: true,
+			maxSuccess:      50,
+			checkMinFault:   true,
+			minFault:        50,
+			checkMaxFault:   true,
+			maxFault:        50,
+		},
+		{
+			name: "scenario no match, falls back to 1/4 client, 1/1 server, loops 100, = 25%",
+			config: unaryClientFaultInjector.UnaryClientInterceptorConfig{
+				Client: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 4,
+				},
+				Server: unaryClientFaultInjector.ModeValue{
+					Mode:  unaryClientFaultInjector.Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+				Scenario: &faultScenario.Scenario{
+					Rules: []faultScenario.Rule{
+						{
+							Match:  faultScenario.Match{Method: "/other.Service/*"},
+							Action: faultScenario.Action{Codes: "10"},
+						},
+					},
+				},
+			},
+			expectErr:       false,
+			loops:           100,
+			checkMinSuccess: true,
+			minSuccess:      75,
+			checkMaxSuccess: true,
+			maxSuccess:      75,
+			checkMinFault:   true,
+			minFault:        25,
+			checkMaxFault:   true,
+			maxFault:        25,
+		},
 	}
 
 	//------------------------------------------------
@@ -341,6 +624,13 @@ func TestComprehensive(t *testing.T) {
 				}
 			}
 
+			// a Scenario built in Go must be validated before use
+			if tt.config.Scenario != nil {
+				if err := tt.config.Scenario.Validate(); err != nil {
+					t.Fatalf("Scenario.Validate() fails: %v", err)
+				}
+			}
+
 			conn, err := grpc.NewClient(
 				address,
 				grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -0,0 +1,149 @@
+package faultScenario
+
+// faultScenario is a declarative fault scenario, with an ordered list of rules
+// which can be checked into a repo as a JSON or YAML file, rather than building
+// UnaryClientInterceptorConfig literals in Go
+//
+// Each rule has a match, a selection, and an action.  The first rule that
+// matches a request decides if the request is faulted.
+
+import (
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/cron"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+)
+
+// Scenario is the list of rules, in order
+// Now is the clock for the rule windows, which defaults to time.Now
+// Tests can set Now, to check the windows without sleeping
+type Scenario struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+
+	Now func() time.Time `json:"-" yaml:"-"`
+
+	// compiled holds the parsed rules, and the rule counters, created by Validate
+	compiled []*compiledRule
+
+	// compileOnce validates a Scenario built in Go, on the first Evaluate, if Validate wasn't called
+	compileOnce sync.Once
+}
+
+// Rule is a single fault rule
+// Name is optional, and is used in the logs
+// Window is optional, and the rule only matches during the window
+type Rule struct {
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	Match  Match  `json:"match,omitempty" yaml:"match,omitempty"`
+	Window Window `json:"window,omitempty" yaml:"window,omitempty"`
+	Select Select `json:"select,omitempty" yaml:"select,omitempty"`
+	Action Action `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// Match selects which requests the rule applies to.  All the fields must match,
+// and an empty field matches all requests
+// Method is the full GRPC method, and can be a glob, e.g. "/grpc.examples.echo.Echo/*"
+// Metadata is a map of metadata (header) keys to the required value
+// Peer is a CIDR, e.g. "127.0.0.0/8" or "::1/128", and is only matched on the server
+type Match struct {
+	Method   string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Peer     string            `json:"peer,omitempty" yaml:"peer,omitempty"`
+}
+
+// Window is when the rule is active, for scheduled outages
+// Start is an RFC3339 time, e.g. "2024-06-01T14:00:00Z"
+// Duration is how long the rule is active, from the Start, or each Period, or each Cron match
+// Period repeats the window, e.g. Duration "30s" and Period "10m" is active for 30s every 10 minutes.
+// Without a Start, the Period is aligned to the unix epoch, so "10m" starts at :00, :10, :20...
+// Cron is a 5 field cron expression, e.g. "0 14 * * 1-5" is 14:00 Monday to Friday, in the
+// local time zone of the clock.  The Cron Duration can be at most 24h
+// A zero Window is always active
+type Window struct {
+	Start    string   `json:"start,omitempty" yaml:"start,omitempty"`
+	Duration Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Period   Duration `json:"period,omitempty" yaml:"period,omitempty"`
+	Cron     string   `json:"cron,omitempty" yaml:"cron,omitempty"`
+}
+
+// Select selects which of the matched requests are faulted
+// Mode is "modulus", "percent", "ppm", "first", or "sequence"
+// An empty Mode faults every matched request
+// The values are the same as the UnaryClientInterceptorConfig ModeValue
+type Select struct {
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Value    int    `json:"value,omitempty" yaml:"value,omitempty"`
+	Offset   int    `json:"offset,omitempty" yaml:"offset,omitempty"`
+	Sequence string `json:"sequence,omitempty" yaml:"sequence,omitempty"`
+	Repeat   bool   `json:"repeat,omitempty" yaml:"repeat,omitempty"`
+}
+
+// Action is the fault
+// Codes is a comma seperated list of GRPC status codes, and one is randomly selected
+// Delay is a duration, e.g. "100ms", which is applied before the fault
+// If Codes is empty, and there is a Delay, the request is only delayed, and is not faulted.
+// If Codes is empty, and there is no Delay, any random code is returned ( like "faultcodes" )
+// Trailers are added to the fault response, and are only supported on the server
+// Corrupt lets the handler run, and then corrupts the response, instead of returning a code
+// e.g. "zero,truncate:message" ( see "faultcorrupt" )
+// Panic panics with the value, instead of returning a code, to test the recovery interceptor.  Server only
+// Cancel cancels the handler context after the duration, e.g. "0s" is immediately, instead of returning a code.  Server only
+type Action struct {
+	Codes    string            `json:"codes,omitempty" yaml:"codes,omitempty"`
+	Delay    Duration          `json:"delay,omitempty" yaml:"delay,omitempty"`
+	Trailers map[string]string `json:"trailers,omitempty" yaml:"trailers,omitempty"`
+	Corrupt  string            `json:"corrupt,omitempty" yaml:"corrupt,omitempty"`
+	Panic    string            `json:"panic,omitempty" yaml:"panic,omitempty"`
+	Cancel   string            `json:"cancel,omitempty" yaml:"cancel,omitempty"`
+}
+
+// Duration is a time.Duration, which is a string in JSON and YAML, e.g. "100ms"
+type Duration time.Duration
+
+type compiledRule struct {
+	rule *Rule
+
+	metadata map[string]string
+	peer     netip.Prefix
+	window   *compiledWindow
+	mode     mode
+	codes    []codes.Code
+	steps    []sequence.Step
+	corrupt  []mutate.Mutation
+	cancel   *time.Duration
+
+	counter atomic.Uint64
+}
+
+type compiledWindow struct {
+	start    time.Time
+	duration time.Duration
+	period   time.Duration
+	cron     *cron.Schedule
+
+	// last caches the most recent cron match, for the current minute
+	last atomic.Pointer[cronLast]
+}
+
+type cronLast struct {
+	minute int64
+	start  time.Time
+	ok     bool
+}
+
+type mode int32
+
+const (
+	modeAlways mode = iota
+	modeModulus
+	modePercent
+	modePPM
+	modeFirst
+	modeSequence
+)
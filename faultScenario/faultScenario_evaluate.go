@@ -0,0 +1,244 @@
+package faultScenario
+
+import (
+	"net"
+	"net/netip"
+	"path"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/pattern"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+)
+
+// Decision is the result of evaluating the scenario for a request
+// Matched is false if no rule matched, and the interceptor should fall back
+// to the normal configuration or headers
+// Fault true means return Code ( after the Delay )
+// Fault false with a Delay means delay the request, and then continue as normal
+// Corrupt, if set, means the response is corrupted, instead of returning Code
+// Panic, if set, is the panic value, instead of returning Code
+// Cancel true means the handler context is cancelled after CancelAfter, instead of returning Code
+type Decision struct {
+	Matched     bool
+	Rule        string
+	Counter     uint64
+	Fault       bool
+	Code        codes.Code
+	Delay       time.Duration
+	Trailers    metadata.MD
+	Corrupt     []mutate.Mutation
+	Panic       string
+	Cancel      bool
+	CancelAfter time.Duration
+}
+
+// Evaluate finds the first rule matching the request, and applies the rule selection
+// peerAddr is the client address, which is nil on the client side
+// Each rule has its own request counter, which only counts matched requests
+// A rule outside of its window doesn't match
+// A Scenario which wasn't validated is validated on the first Evaluate, and if it's
+// invalid, the error is logged, and no rule matches
+func (s *Scenario) Evaluate(method string, md metadata.MD, peerAddr net.Addr) (d Decision) {
+
+	var now time.Time
+
+	for _, c := range s.rules() {
+
+		if !c.matches(method, md, peerAddr) {
+			continue
+		}
+
+		if c.window != nil {
+			if now.IsZero() {
+				now = s.now()
+			}
+			if !c.window.active(now) {
+				continue
+			}
+		}
+
+		d.Matched = true
+		d.Rule = c.rule.Name
+		d.Counter = c.counter.Add(1)
+
+		code, fire := c.selects(d.Counter)
+		if !fire {
+			return d
+		}
+
+		d.Delay = time.Duration(c.rule.Action.Delay)
+
+		// delay only
+		if len(c.codes) == 0 && len(c.corrupt) == 0 && c.rule.Action.Panic == "" && c.cancel == nil &&
+			d.Delay > 0 && c.mode != modeSequence {
+			return d
+		}
+
+		d.Fault = true
+		d.Code = code
+
+		if len(c.rule.Action.Trailers) > 0 {
+			d.Trailers = metadata.New(c.rule.Action.Trailers)
+		}
+
+		d.Corrupt = c.corrupt
+		d.Panic = c.rule.Action.Panic
+		if c.cancel != nil {
+			d.Cancel = true
+			d.CancelAfter = *c.cancel
+		}
+
+		return d
+	}
+
+	return d
+}
+
+// rules returns the compiled rules, and validates the Scenario on the first call, if Validate wasn't called
+func (s *Scenario) rules() []*compiledRule {
+	s.compileOnce.Do(func() {
+		if s.compiled != nil {
+			return
+		}
+		if err := s.Validate(); err != nil {
+			logger.Printf("faultScenario Evaluate invalid scenario, no rules will match error:%v", err)
+		}
+	})
+	return s.compiled
+}
+
+func (s *Scenario) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// active returns true if the time is within the window
+func (w *compiledWindow) active(now time.Time) bool {
+
+	if !w.start.IsZero() && now.Before(w.start) {
+		return false
+	}
+
+	switch {
+	case w.cron != nil:
+		start, ok := w.cronLast(now)
+		return ok && now.Before(start.Add(w.duration))
+
+	case w.period > 0:
+		// without a start, the period is aligned to the unix epoch
+		var base int64
+		if !w.start.IsZero() {
+			base = w.start.UnixNano()
+		}
+		elapsed := time.Duration((now.UnixNano() - base) % int64(w.period))
+		return elapsed < w.duration
+
+	default:
+		return now.Before(w.start.Add(w.duration))
+	}
+}
+
+// cronLast returns the most recent cron match, which only changes each minute, so it is cached
+func (w *compiledWindow) cronLast(now time.Time) (time.Time, bool) {
+
+	minute := now.Unix() / 60
+
+	if l := w.last.Load(); l != nil && l.minute == minute {
+		return l.start, l.ok
+	}
+
+	// check back one extra minute, so the cached result is correct for the whole minute
+	start, ok := w.cron.Last(now.Truncate(time.Minute), w.duration+time.Minute)
+	w.last.Store(&cronLast{minute: minute, start: start, ok: ok})
+
+	return start, ok
+}
+
+// matches returns true if all the match fields match the request
+func (c *compiledRule) matches(method string, md metadata.MD, peerAddr net.Addr) bool {
+
+	if c.rule.Match.Method != "" {
+		if ok, _ := path.Match(c.rule.Match.Method, method); !ok {
+			return false
+		}
+	}
+
+	for k, v := range c.metadata {
+		values := md.Get(k)
+		if len(values) == 0 || values[0] != v {
+			return false
+		}
+	}
+
+	if c.peer.IsValid() {
+		addr, ok := addrFromNetAddr(peerAddr)
+		if !ok || !c.peer.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selects applies the rule selection to the rule counter, and returns the fault code
+func (c *compiledRule) selects(counter uint64) (code codes.Code, fire bool) {
+
+	sel := c.rule.Select
+
+	switch c.mode {
+	case modeModulus:
+		fire = pattern.Modulus(counter, uint64(sel.Value), uint64(sel.Offset))
+	case modePercent:
+		fire = rand.SamplePercent(sel.Value)
+	case modePPM:
+		fire = rand.SamplePPM(sel.Value)
+	case modeFirst:
+		fire = pattern.First(counter, uint64(sel.Value), uint64(sel.Offset))
+	case modeSequence:
+		step := sequence.At(c.steps, counter, sel.Repeat)
+		return step.Code, step.Fault
+	default:
+		fire = true
+	}
+
+	if !fire {
+		return code, false
+	}
+
+	switch len(c.codes) {
+	case 0:
+		code = rand.RandomFaultCode()
+	case 1:
+		code = c.codes[0]
+	default:
+		code = rand.RandomSuppliedFaultCode(&c.codes)
+	}
+
+	return code, true
+}
+
+// addrFromNetAddr converts the peer net.Addr to a netip.Addr
+func addrFromNetAddr(a net.Addr) (addr netip.Addr, ok bool) {
+
+	if a == nil {
+		return addr, false
+	}
+
+	if tcp, isTCP := a.(*net.TCPAddr); isTCP {
+		return tcp.AddrPort().Addr().Unmap(), true
+	}
+
+	ap, err := netip.ParseAddrPort(a.String())
+	if err != nil {
+		return addr, false
+	}
+
+	return ap.Addr().Unmap(), true
+}
@@ -0,0 +1,272 @@
+package faultScenario
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	echoMethod = "/grpc.examples.echo.Echo/UnaryEcho"
+)
+
+var (
+	localPeer  = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 54321}
+	remotePeer = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 54321}
+)
+
+type evaluateTest struct {
+	name     string
+	rules    []Rule
+	method   string
+	md       metadata.MD
+	peer     net.Addr
+	loops    int
+	matched  bool
+	faults   []uint64
+	code     codes.Code
+	delay    time.Duration
+	trailers bool
+	corrupt  int
+	panic    string
+	cancel   bool
+}
+
+// go test -run TestEvaluate -v
+func TestEvaluate(t *testing.T) {
+	tests := []evaluateTest{
+		{
+			name:    "no match method",
+			rules:   []Rule{{Match: Match{Method: "/other.Service/*"}}},
+			method:  echoMethod,
+			loops:   3,
+			matched: false,
+		},
+		{
+			name:    "match method glob, always",
+			rules:   []Rule{{Match: Match{Method: "/grpc.examples.echo.Echo/*"}, Action: Action{Codes: "14"}}},
+			method:  echoMethod,
+			loops:   3,
+			matched: true,
+			faults:  []uint64{1, 2, 3},
+			code:    codes.Unavailable,
+		},
+		{
+			name:    "no match metadata",
+			rules:   []Rule{{Match: Match{Metadata: map[string]string{"faultsession": "test1"}}}},
+			method:  echoMethod,
+			md:      metadata.Pairs("faultsession", "test2"),
+			loops:   3,
+			matched: false,
+		},
+		{
+			name:    "match metadata, modulus 2 offset 1",
+			rules:   []Rule{{Match: Match{Metadata: map[string]string{"FaultSession": "test1"}}, Select: Select{Mode: "modulus", Value: 2, Offset: 1}, Action: Action{Codes: "10"}}},
+			method:  echoMethod,
+			md:      metadata.Pairs("faultsession", "test1"),
+			loops:   6,
+			matched: true,
+			faults:  []uint64{1, 3, 5},
+			code:    codes.Aborted,
+		},
+		{
+			name:    "no match peer",
+			rules:   []Rule{{Match: Match{Peer: "127.0.0.0/8"}}},
+			method:  echoMethod,
+			peer:    remotePeer,
+			loops:   3,
+			matched: false,
+		},
+		{
+			name:    "no match peer on the client",
+			rules:   []Rule{{Match: Match{Peer: "127.0.0.0/8"}}},
+			method:  echoMethod,
+			loops:   3,
+			matched: false,
+		},
+		{
+			name:    "match peer, first 2",
+			rules:   []Rule{{Match: Match{Peer: "127.0.0.0/8"}, Select: Select{Mode: "first", Value: 2}, Action: Action{Codes: "4"}}},
+			method:  echoMethod,
+			peer:    localPeer,
+			loops:   5,
+			matched: true,
+			faults:  []uint64{1, 2},
+			code:    codes.DeadlineExceeded,
+		},
+		{
+			name:    "sequence",
+			rules:   []Rule{{Select: Select{Mode: "sequence", Sequence: "ok,14,14,ok"}}},
+			method:  echoMethod,
+			loops:   8,
+			matched: true,
+			faults:  []uint64{2, 3},
+			code:    codes.Unavailable,
+		},
+		{
+			name:    "first rule wins",
+			rules:   []Rule{{Select: Select{Mode: "first", Value: 1}, Action: Action{Codes: "10"}}, {Action: Action{Codes: "14"}}},
+			method:  echoMethod,
+			loops:   3,
+			matched: true,
+			faults:  []uint64{1},
+			code:    codes.Aborted,
+		},
+		{
+			name:    "delay only",
+			rules:   []Rule{{Action: Action{Delay: Duration(100 * time.Millisecond)}}},
+			method:  echoMethod,
+			loops:   3,
+			matched: true,
+			faults:  nil,
+			delay:   100 * time.Millisecond,
+		},
+		{
+			name:     "code, delay, and trailers",
+			rules:    []Rule{{Action: Action{Codes: "14", Delay: Duration(time.Millisecond), Trailers: map[string]string{"a": "b"}}}},
+			method:   echoMethod,
+			loops:    1,
+			matched:  true,
+			faults:   []uint64{1},
+			code:     codes.Unavailable,
+			delay:    time.Millisecond,
+			trailers: true,
+		},
+		{
+			name:    "corrupt and delay",
+			rules:   []Rule{{Action: Action{Codes: "14", Corrupt: "zero,clear:id", Delay: Duration(time.Millisecond)}}},
+			method:  echoMethod,
+			loops:   1,
+			matched: true,
+			faults:  []uint64{1},
+			code:    codes.Unavailable,
+			delay:   time.Millisecond,
+			corrupt: 2,
+		},
+		{
+			name:    "panic",
+			rules:   []Rule{{Action: Action{Codes: "13", Panic: "boom"}}},
+			method:  echoMethod,
+			loops:   1,
+			matched: true,
+			faults:  []uint64{1},
+			code:    codes.Internal,
+			panic:   "boom",
+		},
+		{
+			name:    "cancel",
+			rules:   []Rule{{Action: Action{Codes: "1", Cancel: "0s"}}},
+			method:  echoMethod,
+			loops:   1,
+			matched: true,
+			faults:  []uint64{1},
+			code:    codes.Canceled,
+			cancel:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			s := &Scenario{Rules: tt.rules}
+			if err := s.Validate(); err != nil {
+				t.Fatalf("test: %s, Validate error: %v", tt.name, err)
+			}
+
+			var faults []uint64
+			for i := 1; i <= tt.loops; i++ {
+				d := s.Evaluate(tt.method, tt.md, tt.peer)
+				if d.Matched != tt.matched {
+					t.Fatalf("test: %s, i:%d Matched:%t != tt.matched:%t", tt.name, i, d.Matched, tt.matched)
+				}
+				if !d.Fault {
+					if d.Delay != tt.delay && tt.faults == nil && tt.matched {
+						t.Errorf("test: %s, i:%d Delay:%s != tt.delay:%s", tt.name, i, d.Delay, tt.delay)
+					}
+					continue
+				}
+				faults = append(faults, uint64(i))
+				if d.Code != tt.code {
+					t.Errorf("test: %s, i:%d Code:%s != tt.code:%s", tt.name, i, d.Code, tt.code)
+				}
+				if d.Delay != tt.delay {
+					t.Errorf("test: %s, i:%d Delay:%s != tt.delay:%s", tt.name, i, d.Delay, tt.delay)
+				}
+				if (d.Trailers != nil) != tt.trailers {
+					t.Errorf("test: %s, i:%d Trailers:%v", tt.name, i, d.Trailers)
+				}
+				if d.Cancel != tt.cancel {
+					t.Errorf("test: %s, i:%d Cancel:%t != tt.cancel:%t", tt.name, i, d.Cancel, tt.cancel)
+				}
+				if d.Panic != tt.panic {
+					t.Errorf("test: %s, i:%d Panic:%q != tt.panic:%q", tt.name, i, d.Panic, tt.panic)
+				}
+				if len(d.Corrupt) != tt.corrupt {
+					t.Errorf("test: %s, i:%d Corrupt:%v != tt.corrupt:%d", tt.name, i, d.Corrupt, tt.corrupt)
+				}
+			}
+
+			if len(faults) != len(tt.faults) {
+				t.Fatalf("test: %s, faults:%v != tt.faults:%v", tt.name, faults, tt.faults)
+			}
+			for i := range faults {
+				if faults[i] != tt.faults[i] {
+					t.Errorf("test: %s, faults:%v != tt.faults:%v", tt.name, faults, tt.faults)
+				}
+			}
+		})
+	}
+}
+
+type evaluateNotValidatedTest struct {
+	name    string
+	rules   []Rule
+	matched bool
+	fault   bool
+}
+
+// go test -run TestEvaluateNotValidated -v
+func TestEvaluateNotValidated(t *testing.T) {
+	tests := []evaluateNotValidatedTest{
+		{
+			name:    "valid, compiled on the first Evaluate",
+			rules:   []Rule{{Action: Action{Codes: "14"}}},
+			matched: true,
+			fault:   true,
+		},
+		{
+			name:    "invalid, logged and nothing matches",
+			rules:   []Rule{{Action: Action{Codes: "blah"}}},
+			matched: false,
+			fault:   false,
+		},
+		{
+			name:    "no rules",
+			matched: false,
+			fault:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			s := &Scenario{Rules: tt.rules}
+
+			for i := 1; i <= 2; i++ {
+				d := s.Evaluate(echoMethod, nil, nil)
+				if d.Matched != tt.matched {
+					t.Errorf("test: %s, i:%d Matched:%t != tt.matched:%t", tt.name, i, d.Matched, tt.matched)
+				}
+				if d.Fault != tt.fault {
+					t.Errorf("test: %s, i:%d Fault:%t != tt.fault:%t", tt.name, i, d.Fault, tt.fault)
+				}
+				if tt.matched && d.Counter != uint64(i) {
+					t.Errorf("test: %s, i:%d Counter:%d", tt.name, i, d.Counter)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package faultScenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the scenario file format
+type Format int32
+
+const (
+	JSON Format = iota
+	YAML Format = 1
+)
+
+var (
+	errUnknownFormat = errors.New("unknown scenario format, use .json, .yaml, or .yml")
+)
+
+// Load reads, parses, and validates the scenario file
+// The format is selected by the file extension: .json, .yaml, or .yml
+func Load(filename string) (*Scenario, error) {
+
+	format, err := FormatFromFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return s, nil
+}
+
+// FormatFromFilename returns the Format for the file extension
+func FormatFromFilename(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return JSON, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	}
+	return JSON, errUnknownFormat
+}
+
+// Parse parses and validates the scenario
+// Unknown fields are an error, to catch typos in the scenario
+func Parse(data []byte, format Format) (*Scenario, error) {
+
+	s := new(Scenario)
+
+	switch format {
+	case JSON:
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+		if err := d.Decode(s); err != nil {
+			return nil, fmt.Errorf("json decode error: %w", err)
+		}
+	case YAML:
+		d := yaml.NewDecoder(bytes.NewReader(data))
+		d.KnownFields(true)
+		if err := d.Decode(s); err != nil {
+			return nil, fmt.Errorf("yaml decode error: %w", err)
+		}
+	default:
+		return nil, errUnknownFormat
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	return d.parse(str)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var str string
+	if err := value.Decode(&str); err != nil {
+		return err
+	}
+	return d.parse(str)
+}
+
+func (d *Duration) parse(str string) error {
+	pd, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(pd)
+	return nil
+}
@@ -0,0 +1,194 @@
+package faultScenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testYAML = `
+rules:
+  - name: echo unavailable
+    match:
+      method: /grpc.examples.echo.Echo/*
+      metadata:
+        faultsession: test1
+      peer: 127.0.0.0/8
+    select:
+      mode: modulus
+      value: 2
+      offset: 1
+    action:
+      codes: "14"
+      delay: 100ms
+      trailers:
+        fault-rule: echo
+  - name: everything else
+    select:
+      mode: sequence
+      sequence: ok,14,14,ok,4,ok
+      repeat: true
+`
+
+const testJSON = `{
+  "rules": [
+    {
+      "name": "echo unavailable",
+      "match": {
+        "method": "/grpc.examples.echo.Echo/*",
+        "metadata": {"faultsession": "test1"},
+        "peer": "127.0.0.0/8"
+      },
+      "select": {"mode": "modulus", "value": 2, "offset": 1},
+      "action": {"codes": "14", "delay": "100ms", "trailers": {"fault-rule": "echo"}}
+    },
+    {
+      "name": "everything else",
+      "select": {"mode": "sequence", "sequence": "ok,14,14,ok,4,ok", "repeat": true}
+    }
+  ]
+}`
+
+type parseTest struct {
+	name      string
+	data      string
+	format    Format
+	expectErr bool
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{
+			name:      "valid yaml",
+			data:      testYAML,
+			format:    YAML,
+			expectErr: false,
+		},
+		{
+			name:      "valid json",
+			data:      testJSON,
+			format:    JSON,
+			expectErr: false,
+		},
+		{
+			name:      "invalid yaml unknown field",
+			data:      "rules:\n  - name: a\n    selct:\n      mode: modulus\n",
+			format:    YAML,
+			expectErr: true,
+		},
+		{
+			name:      "invalid json unknown field",
+			data:      `{"rules":[{"name":"a","selct":{"mode":"modulus"}}]}`,
+			format:    JSON,
+			expectErr: true,
+		},
+		{
+			name:      "invalid json delay",
+			data:      `{"rules":[{"action":{"delay":"blah"}}]}`,
+			format:    JSON,
+			expectErr: true,
+		},
+		{
+			name:      "invalid yaml no rules",
+			data:      "rules: []\n",
+			format:    YAML,
+			expectErr: true,
+		},
+		{
+			name:      "invalid json as yaml format",
+			data:      "{",
+			format:    YAML,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.format)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+		})
+	}
+}
+
+// go test -run TestParseYAMLJSONEqual -v
+func TestParseYAMLJSONEqual(t *testing.T) {
+
+	y, err := Parse([]byte(testYAML), YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := Parse([]byte(testJSON), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(y.Rules) != 2 || len(j.Rules) != 2 {
+		t.Fatalf("len(y.Rules):%d len(j.Rules):%d != 2", len(y.Rules), len(j.Rules))
+	}
+
+	if time.Duration(y.Rules[0].Action.Delay) != 100*time.Millisecond {
+		t.Errorf("yaml delay:%s != 100ms", y.Rules[0].Action.Delay)
+	}
+
+	if y.Rules[0].Action.Delay != j.Rules[0].Action.Delay ||
+		y.Rules[0].Match.Peer != j.Rules[0].Match.Peer ||
+		y.Rules[1].Select.Sequence != j.Rules[1].Select.Sequence {
+		t.Errorf("yaml:%v != json:%v", y.Rules, j.Rules)
+	}
+}
+
+type formatFromFilenameTest struct {
+	filename  string
+	format    Format
+	expectErr bool
+}
+
+// go test -run TestFormatFromFilename -v
+func TestFormatFromFilename(t *testing.T) {
+	tests := []formatFromFilenameTest{
+		{filename: "scenario.json", format: JSON, expectErr: false},
+		{filename: "scenario.yaml", format: YAML, expectErr: false},
+		{filename: "/tmp/scenario.YML", format: YAML, expectErr: false},
+		{filename: "scenario.txt", expectErr: true},
+		{filename: "scenario", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			format, err := FormatFromFilename(tt.filename)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.filename, tt.expectErr, err != nil)
+			}
+			if !tt.expectErr && format != tt.format {
+				t.Errorf("test: %s, format:%d != tt.format:%d", tt.filename, format, tt.format)
+			}
+		})
+	}
+}
+
+// go test -run TestLoad -v
+func TestLoad(t *testing.T) {
+
+	dir := t.TempDir()
+
+	filename := filepath.Join(dir, "scenario.yaml")
+	if err := os.WriteFile(filename, []byte(testYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(filename)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(s.Rules) != 2 {
+		t.Errorf("len(s.Rules):%d != 2", len(s.Rules))
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Load missing file expected error")
+	}
+}
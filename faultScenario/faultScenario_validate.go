@@ -0,0 +1,254 @@
+package faultScenario
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/cron"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	// maxCronDuration limits how far back the cron schedule is checked
+	maxCronDuration = 24 * time.Hour
+)
+
+var (
+	errWindowDuration     = errors.New("duration must be greater than zero")
+	errWindowStart        = errors.New("must have a start, period, or cron")
+	errWindowPeriod       = errors.New("period must be greater than, or equal to, the duration")
+	errWindowCronPeriod   = errors.New("cron and period can not both be set")
+	errWindowCronDuration = errors.New("cron duration must be 24h or less")
+
+	errNoRules     = errors.New("scenario has no rules")
+	errInvalidMode = errors.New("invalid select mode, use modulus, percent, ppm, first, or sequence")
+)
+
+// Validate checks every rule, and prepares the rules for Evaluate
+// Parse and Load call Validate, so it only needs to be called for
+// a Scenario built in Go.  Without Validate, Evaluate validates the
+// Scenario on the first call, and logs the error
+func (s *Scenario) Validate() error {
+
+	if len(s.Rules) == 0 {
+		return errNoRules
+	}
+
+	compiled := make([]*compiledRule, 0, len(s.Rules))
+	for i := range s.Rules {
+		c, err := compileRule(&s.Rules[i])
+		if err != nil {
+			return fmt.Errorf("rule %d %q: %w", i, s.Rules[i].Name, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.compiled = compiled
+
+	return nil
+}
+
+func compileRule(r *Rule) (*compiledRule, error) {
+
+	c := &compiledRule{rule: r}
+
+	if err := validateMatch(r.Match, c); err != nil {
+		return nil, fmt.Errorf("match: %w", err)
+	}
+
+	if err := validateWindow(r.Window, c); err != nil {
+		return nil, fmt.Errorf("window: %w", err)
+	}
+
+	if err := validateSelect(r.Select, c); err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	if err := validateAction(r.Action, c); err != nil {
+		return nil, fmt.Errorf("action: %w", err)
+	}
+
+	return c, nil
+}
+
+func validateMatch(m Match, c *compiledRule) error {
+
+	if m.Method != "" {
+		if _, err := path.Match(m.Method, ""); err != nil {
+			return fmt.Errorf("method %q: %w", m.Method, err)
+		}
+	}
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	if len(m.Metadata) > 0 {
+		c.metadata = make(map[string]string, len(m.Metadata))
+		for k, v := range m.Metadata {
+			if k == "" {
+				return errors.New("metadata key is empty")
+			}
+			c.metadata[strings.ToLower(k)] = v
+		}
+	}
+
+	if m.Peer != "" {
+		prefix, err := netip.ParsePrefix(m.Peer)
+		if err != nil {
+			return fmt.Errorf("peer: %w", err)
+		}
+		c.peer = prefix.Masked()
+	}
+
+	return nil
+}
+
+func validateWindow(w Window, c *compiledRule) error {
+
+	if w == (Window{}) {
+		return nil
+	}
+
+	cw := &compiledWindow{
+		duration: time.Duration(w.Duration),
+		period:   time.Duration(w.Period),
+	}
+
+	if cw.duration <= 0 {
+		return errWindowDuration
+	}
+
+	if w.Start == "" && cw.period == 0 && w.Cron == "" {
+		return errWindowStart
+	}
+
+	if w.Start != "" {
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+		cw.start = start
+	}
+
+	if cw.period < 0 || (cw.period > 0 && cw.duration > cw.period) {
+		return errWindowPeriod
+	}
+
+	if w.Cron != "" {
+		if cw.period > 0 {
+			return errWindowCronPeriod
+		}
+		if cw.duration > maxCronDuration {
+			return errWindowCronDuration
+		}
+		sched, err := cron.Parse(w.Cron)
+		if err != nil {
+			return fmt.Errorf("cron: %w", err)
+		}
+		cw.cron = &sched
+	}
+
+	c.window = cw
+
+	return nil
+}
+
+func validateSelect(sel Select, c *compiledRule) error {
+
+	switch strings.ToLower(sel.Mode) {
+	case "":
+		c.mode = modeAlways
+	case "modulus":
+		c.mode = modeModulus
+		if _, err := validate.ValidateModulus(int64(sel.Value)); err != nil {
+			return err
+		}
+	case "percent":
+		c.mode = modePercent
+		if _, err := validate.ValidatePercent(int64(sel.Value)); err != nil {
+			return err
+		}
+	case "ppm":
+		c.mode = modePPM
+		if _, err := validate.ValidatePPM(int64(sel.Value)); err != nil {
+			return err
+		}
+	case "first":
+		c.mode = modeFirst
+		if _, err := validate.ValidateFirst(int64(sel.Value)); err != nil {
+			return err
+		}
+	case "sequence":
+		c.mode = modeSequence
+		steps, err := sequence.Parse(sel.Sequence)
+		if err != nil {
+			return fmt.Errorf("sequence: %w", err)
+		}
+		c.steps = steps
+	default:
+		return errInvalidMode
+	}
+
+	if _, err := validate.ValidateOffset(int64(sel.Offset)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateAction(a Action, c *compiledRule) error {
+
+	if a.Codes != "" {
+		parts := strings.Split(a.Codes, ",")
+		for i := 0; i < len(parts); i++ {
+			code, err := strconv.ParseInt(strings.TrimSpace(parts[i]), 0, 64)
+			if err != nil {
+				return fmt.Errorf("codes: %w", err)
+			}
+			cv, err := validate.ValidateCode(code)
+			if err != nil {
+				return fmt.Errorf("codes: %w", err)
+			}
+			c.codes = append(c.codes, codes.Code(cv))
+		}
+	}
+
+	if _, err := validate.ValidateDelay(time.Duration(a.Delay)); err != nil {
+		return err
+	}
+
+	for k := range a.Trailers {
+		if k == "" {
+			return errors.New("trailer key is empty")
+		}
+	}
+
+	if a.Corrupt != "" {
+		ms, err := mutate.Parse(a.Corrupt)
+		if err != nil {
+			return fmt.Errorf("corrupt: %w", err)
+		}
+		c.corrupt = ms
+	}
+
+	if a.Cancel != "" {
+		d, err := time.ParseDuration(a.Cancel)
+		if err != nil {
+			return fmt.Errorf("cancel: %w", err)
+		}
+		if _, err := validate.ValidateDelay(d); err != nil {
+			return fmt.Errorf("cancel: %w", err)
+		}
+		c.cancel = &d
+	}
+
+	return nil
+}
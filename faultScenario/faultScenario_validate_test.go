@@ -0,0 +1,172 @@
+package faultScenario
+
+import (
+	"testing"
+	"time"
+)
+
+type validateTest struct {
+	name      string
+	rule      Rule
+	expectErr bool
+}
+
+// go test -run TestValidate -v
+func TestValidate(t *testing.T) {
+	tests := []validateTest{
+		{
+			name:      "valid empty rule faults everything",
+			rule:      Rule{},
+			expectErr: false,
+		},
+		{
+			name: "valid full rule",
+			rule: Rule{
+				Match:  Match{Method: "/grpc.examples.echo.Echo/*", Metadata: map[string]string{"X-Test": "a"}, Peer: "::1/128"},
+				Select: Select{Mode: "Percent", Value: 10},
+				Action: Action{Codes: "10, 12,14", Delay: Duration(time.Second), Trailers: map[string]string{"a": "b"}},
+			},
+			expectErr: false,
+		},
+		{
+			name:      "invalid method glob",
+			rule:      Rule{Match: Match{Method: "/echo/["}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid peer",
+			rule:      Rule{Match: Match{Peer: "127.0.0.1"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid mode",
+			rule:      Rule{Select: Select{Mode: "blah", Value: 1}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid modulus 0",
+			rule:      Rule{Select: Select{Mode: "modulus"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid percent 101",
+			rule:      Rule{Select: Select{Mode: "percent", Value: 101}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid ppm 0",
+			rule:      Rule{Select: Select{Mode: "ppm"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid first 0",
+			rule:      Rule{Select: Select{Mode: "first"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid sequence",
+			rule:      Rule{Select: Select{Mode: "sequence", Sequence: "ok,17"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid offset",
+			rule:      Rule{Select: Select{Mode: "modulus", Value: 1, Offset: -1}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid codes",
+			rule:      Rule{Action: Action{Codes: "10,blah"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid code 17",
+			rule:      Rule{Action: Action{Codes: "17"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid delay",
+			rule:      Rule{Action: Action{Delay: Duration(time.Hour)}},
+			expectErr: true,
+		},
+		{
+			name:      "valid corrupt",
+			rule:      Rule{Action: Action{Corrupt: "zero,truncate:message"}},
+			expectErr: false,
+		},
+		{
+			name:      "invalid corrupt",
+			rule:      Rule{Action: Action{Corrupt: "blah"}},
+			expectErr: true,
+		},
+		{
+			name:      "valid cancel immediately",
+			rule:      Rule{Action: Action{Cancel: "0s"}},
+			expectErr: false,
+		},
+		{
+			name:      "invalid cancel",
+			rule:      Rule{Action: Action{Cancel: "1h"}},
+			expectErr: true,
+		},
+		{
+			name:      "valid window start",
+			rule:      Rule{Window: Window{Start: "2024-06-01T14:00:00Z", Duration: Duration(5 * time.Minute)}},
+			expectErr: false,
+		},
+		{
+			name:      "valid window period",
+			rule:      Rule{Window: Window{Duration: Duration(30 * time.Second), Period: Duration(10 * time.Minute)}},
+			expectErr: false,
+		},
+		{
+			name:      "valid window cron",
+			rule:      Rule{Window: Window{Cron: "0 14 * * 1-5", Duration: Duration(5 * time.Minute)}},
+			expectErr: false,
+		},
+		{
+			name:      "invalid window no duration",
+			rule:      Rule{Window: Window{Start: "2024-06-01T14:00:00Z"}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window duration only",
+			rule:      Rule{Window: Window{Duration: Duration(time.Minute)}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window start",
+			rule:      Rule{Window: Window{Start: "14:00", Duration: Duration(time.Minute)}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window duration greater than period",
+			rule:      Rule{Window: Window{Duration: Duration(time.Hour), Period: Duration(time.Minute)}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window cron and period",
+			rule:      Rule{Window: Window{Cron: "* * * * *", Duration: Duration(time.Second), Period: Duration(time.Minute)}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window cron",
+			rule:      Rule{Window: Window{Cron: "0 25 * * *", Duration: Duration(time.Minute)}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid window cron duration",
+			rule:      Rule{Window: Window{Cron: "0 14 * * *", Duration: Duration(25 * time.Hour)}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scenario{Rules: []Rule{tt.rule}}
+			err := s.Validate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package faultScenario
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultReloadInterval is the polling interval used when the interval is zero (0)
+	DefaultReloadInterval = 5 * time.Second
+)
+
+var (
+	errWatcherFilename = errors.New("watcher filename is empty")
+
+	logger = log.New(os.Stderr, "", log.Ldate|log.Lmicroseconds)
+)
+
+// Watcher polls a scenario file, and swaps the active scenario when the file changes
+// A file which fails to load or validate is logged, and the previous scenario is kept
+// Rules which are unchanged keep their request counter, so a reload doesn't restart
+// the modulus, first, or sequence pattern of the other rules
+type Watcher struct {
+	filename string
+	interval time.Duration
+
+	current atomic.Pointer[Scenario]
+
+	// mu protects the file state, so Check can be called while Run is polling
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	data    []byte
+
+	// check is optional, and rejects a reloaded scenario, see SetCheck
+	check func(*Scenario) error
+
+	reloads atomic.Uint64
+	errors  atomic.Uint64
+}
+
+// NewWatcher loads the scenario file, which must be valid
+// Interval zero (0) uses DefaultReloadInterval
+func NewWatcher(filename string, interval time.Duration) (*Watcher, error) {
+
+	if filename == "" {
+		return nil, errWatcherFilename
+	}
+
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	w := &Watcher{
+		filename: filename,
+		interval: interval,
+	}
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, s, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+	w.data = data
+	w.current.Store(s)
+
+	return w, nil
+}
+
+// Scenario returns the active scenario
+func (w *Watcher) Scenario() *Scenario {
+	return w.current.Load()
+}
+
+// Reloads is the number of times the scenario has been swapped
+func (w *Watcher) Reloads() uint64 {
+	return w.reloads.Load()
+}
+
+// Errors is the number of times the file failed to load or validate
+func (w *Watcher) Errors() uint64 {
+	return w.errors.Load()
+}
+
+// SetCheck sets an extra check for the scenario, e.g. actions the caller doesn't support
+// The active scenario is checked now, and each reloaded scenario is checked before it's swapped,
+// so a reload which fails the check is logged, and the previous scenario is kept
+func (w *Watcher) SetCheck(check func(*Scenario) error) error {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := check(w.current.Load()); err != nil {
+		return err
+	}
+
+	w.check = check
+
+	return nil
+}
+
+// Run polls the file every interval, until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Check(); err != nil {
+				logger.Printf("faultScenario watcher %s error:%v, keeping the previous scenario", w.filename, err)
+			}
+		}
+	}
+}
+
+// Check checks the file once, and swaps the scenario if the file has changed
+// and the new scenario is valid.  reloaded is true if the scenario was swapped
+func (w *Watcher) Check() (reloaded bool, err error) {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fi, err := os.Stat(w.filename)
+	if err != nil {
+		w.errors.Add(1)
+		return false, err
+	}
+
+	if fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return false, nil
+	}
+
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+
+	data, s, err := w.load()
+	if err != nil {
+		w.errors.Add(1)
+		return false, err
+	}
+
+	if w.check != nil {
+		if err := w.check(s); err != nil {
+			w.errors.Add(1)
+			return false, fmt.Errorf("%s: %w", w.filename, err)
+		}
+	}
+
+	// the file was touched, but the content is the same
+	if bytes.Equal(data, w.data) {
+		return false, nil
+	}
+
+	old := w.current.Load()
+	s.keepCounters(old)
+	s.Now = old.Now
+
+	w.data = data
+	w.current.Store(s)
+	r := w.reloads.Add(1)
+
+	diff := ruleDiff(old.Rules, s.Rules)
+	if len(diff) == 0 {
+		logger.Printf("faultScenario watcher %s reload:%d no rule changes", w.filename, r)
+	}
+	for _, line := range diff {
+		logger.Printf("faultScenario watcher %s reload:%d %s", w.filename, r, line)
+	}
+
+	return true, nil
+}
+
+func (w *Watcher) load() ([]byte, *Scenario, error) {
+
+	format, err := FormatFromFilename(w.filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(w.filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := Parse(data, format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", w.filename, err)
+	}
+
+	return data, s, nil
+}
+
+// keepCounters copies the request counters of the unchanged rules from the old scenario
+func (s *Scenario) keepCounters(old *Scenario) {
+
+	if old == nil {
+		return
+	}
+
+	oldRules := make(map[string]*compiledRule, len(old.compiled))
+	for i, c := range old.compiled {
+		oldRules[ruleKey(i, c.rule)] = c
+	}
+
+	for i, c := range s.compiled {
+		o, ok := oldRules[ruleKey(i, c.rule)]
+		if !ok || !reflect.DeepEqual(*o.rule, *c.rule) {
+			continue
+		}
+		c.counter.Store(o.counter.Load())
+	}
+}
+
+// ruleDiff describes the added, changed, and removed rules
+// Rules are compared by name, or by position if the rule has no name
+func ruleDiff(old []Rule, rules []Rule) (diff []string) {
+
+	oldRules := make(map[string]*Rule, len(old))
+	for i := range old {
+		oldRules[ruleKey(i, &old[i])] = &old[i]
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		k := ruleKey(i, &rules[i])
+		seen[k] = true
+
+		o, ok := oldRules[k]
+		switch {
+		case !ok:
+			diff = append(diff, "added rule "+k)
+		case !reflect.DeepEqual(*o, rules[i]):
+			diff = append(diff, "changed rule "+k)
+		}
+	}
+
+	for i := range old {
+		k := ruleKey(i, &old[i])
+		if !seen[k] {
+			diff = append(diff, "removed rule "+k)
+		}
+	}
+
+	return diff
+}
+
+func ruleKey(i int, r *Rule) string {
+	if r.Name != "" {
+		return strconv.Quote(r.Name)
+	}
+	return "#" + strconv.Itoa(i)
+}
@@ -0,0 +1,225 @@
+package faultScenario
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ruleDiffTest struct {
+	name  string
+	old   []Rule
+	rules []Rule
+	diff  []string
+}
+
+// go test -run TestRuleDiff -v
+func TestRuleDiff(t *testing.T) {
+	tests := []ruleDiffTest{
+		{
+			name:  "same",
+			old:   []Rule{{Name: "a", Action: Action{Codes: "14"}}},
+			rules: []Rule{{Name: "a", Action: Action{Codes: "14"}}},
+			diff:  nil,
+		},
+		{
+			name:  "changed codes",
+			old:   []Rule{{Name: "a", Action: Action{Codes: "14"}}},
+			rules: []Rule{{Name: "a", Action: Action{Codes: "10"}}},
+			diff:  []string{`changed rule "a"`},
+		},
+		{
+			name:  "added and removed",
+			old:   []Rule{{Name: "a"}, {Name: "b"}},
+			rules: []Rule{{Name: "a"}, {Name: "c"}},
+			diff:  []string{`added rule "c"`, `removed rule "b"`},
+		},
+		{
+			name:  "no name, by position",
+			old:   []Rule{{Action: Action{Codes: "14"}}},
+			rules: []Rule{{Action: Action{Codes: "14"}}, {Action: Action{Codes: "4"}}},
+			diff:  []string{"added rule #1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := ruleDiff(tt.old, tt.rules)
+			if !reflect.DeepEqual(diff, tt.diff) {
+				t.Errorf("test: %s, diff:%q != tt.diff:%q", tt.name, diff, tt.diff)
+			}
+		})
+	}
+}
+
+const (
+	watchYAMLv1 = `
+rules:
+  - name: a
+    select:
+      mode: modulus
+      value: 2
+    action:
+      codes: "14"
+  - name: b
+    action:
+      codes: "4"
+`
+	watchYAMLv2 = `
+rules:
+  - name: a
+    select:
+      mode: modulus
+      value: 2
+    action:
+      codes: "14"
+  - name: b
+    action:
+      codes: "10"
+`
+	watchYAMLInvalid = `
+rules:
+  - name: a
+    action:
+      codes: "17"
+`
+)
+
+// go test -run TestWatcherCheck -v
+func TestWatcherCheck(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "scenario.yaml")
+	modTime := time.Now().Add(-time.Hour)
+
+	write := func(data string) {
+		if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// make sure the modification time changes, even on a coarse clock
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(watchYAMLv1)
+
+	w, err := NewWatcher(filename, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher error:%v", err)
+	}
+
+	v1 := w.Scenario()
+	v1.Evaluate("/a", nil, nil)
+
+	reloaded, err := w.Check()
+	if reloaded || err != nil {
+		t.Errorf("unchanged file, reloaded:%t err:%v", reloaded, err)
+	}
+
+	write(watchYAMLv2)
+
+	reloaded, err = w.Check()
+	if !reloaded || err != nil {
+		t.Fatalf("changed file, reloaded:%t err:%v", reloaded, err)
+	}
+
+	v2 := w.Scenario()
+	if v2 == v1 {
+		t.Fatal("scenario was not swapped")
+	}
+	if v2.Rules[1].Action.Codes != "10" {
+		t.Errorf("rule b codes:%s != 10", v2.Rules[1].Action.Codes)
+	}
+
+	// rule a is unchanged, so the counter continues, and the second request faults
+	if d := v2.Evaluate("/a", nil, nil); d.Counter != 2 || !d.Fault {
+		t.Errorf("rule a counter:%d fault:%t, expected counter:2 fault:true", d.Counter, d.Fault)
+	}
+
+	write(watchYAMLInvalid)
+
+	reloaded, err = w.Check()
+	if reloaded || err == nil {
+		t.Errorf("invalid file, reloaded:%t err:%v", reloaded, err)
+	}
+	if w.Scenario() != v2 {
+		t.Error("invalid file replaced the scenario")
+	}
+
+	write(watchYAMLv2)
+
+	reloaded, err = w.Check()
+	if reloaded || err != nil {
+		t.Errorf("same content, reloaded:%t err:%v", reloaded, err)
+	}
+
+	if w.Reloads() != 1 || w.Errors() != 1 {
+		t.Errorf("reloads:%d errors:%d, expected 1 and 1", w.Reloads(), w.Errors())
+	}
+}
+
+// go test -run TestWatcherSetCheck -v
+func TestWatcherSetCheck(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "scenario.yaml")
+	modTime := time.Now().Add(-time.Hour)
+
+	write := func(data string) {
+		if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// rejects the code 10
+	check := func(s *Scenario) error {
+		for _, r := range s.Rules {
+			if r.Action.Codes == "10" {
+				return errors.New("code 10 is not supported")
+			}
+		}
+		return nil
+	}
+
+	write(watchYAMLv2)
+
+	w, err := NewWatcher(filename, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher error:%v", err)
+	}
+	if err := w.SetCheck(check); err == nil {
+		t.Error("SetCheck accepted the active scenario, with code 10")
+	}
+
+	write(watchYAMLv1)
+
+	w, err = NewWatcher(filename, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher error:%v", err)
+	}
+	if err := w.SetCheck(check); err != nil {
+		t.Fatalf("SetCheck error:%v", err)
+	}
+
+	v1 := w.Scenario()
+
+	write(watchYAMLv2)
+
+	reloaded, err := w.Check()
+	if reloaded || err == nil {
+		t.Errorf("check failed, reloaded:%t err:%v", reloaded, err)
+	}
+	if w.Scenario() != v1 {
+		t.Error("a scenario failing the check replaced the scenario")
+	}
+	if w.Reloads() != 0 || w.Errors() != 1 {
+		t.Errorf("reloads:%d errors:%d, expected 0 and 1", w.Reloads(), w.Errors())
+	}
+}
@@ -0,0 +1,102 @@
+package faultScenario
+
+import (
+	"testing"
+	"time"
+)
+
+type windowTest struct {
+	name   string
+	window Window
+	now    time.Duration
+	active bool
+}
+
+// go test -run TestWindow -v
+func TestWindow(t *testing.T) {
+
+	// 2024-06-03 was a Monday
+	base := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+	start := base.Format(time.RFC3339)
+
+	fiveMinutes := Window{Start: start, Duration: Duration(5 * time.Minute)}
+	every10m := Window{Duration: Duration(30 * time.Second), Period: Duration(10 * time.Minute)}
+	startEvery10m := Window{Start: base.Add(time.Minute).Format(time.RFC3339), Duration: Duration(30 * time.Second), Period: Duration(10 * time.Minute)}
+	cron := Window{Cron: "0 14 * * 1-5", Duration: Duration(5 * time.Minute)}
+
+	tests := []windowTest{
+		{name: "start, before", window: fiveMinutes, now: -time.Second, active: false},
+		{name: "start, at start", window: fiveMinutes, now: 0, active: true},
+		{name: "start, during", window: fiveMinutes, now: 4*time.Minute + 59*time.Second, active: true},
+		{name: "start, at end", window: fiveMinutes, now: 5 * time.Minute, active: false},
+		{name: "period, during", window: every10m, now: 20*time.Minute + 29*time.Second, active: true},
+		{name: "period, after", window: every10m, now: 20*time.Minute + 30*time.Second, active: false},
+		{name: "period with start, before start", window: startEvery10m, now: 10 * time.Second, active: false},
+		{name: "period with start, first", window: startEvery10m, now: time.Minute + 10*time.Second, active: true},
+		{name: "period with start, second", window: startEvery10m, now: 11*time.Minute + 10*time.Second, active: true},
+		{name: "period with start, between", window: startEvery10m, now: 5 * time.Minute, active: false},
+		{name: "cron, before", window: cron, now: -time.Second, active: false},
+		{name: "cron, during", window: cron, now: 2 * time.Minute, active: true},
+		{name: "cron, end", window: cron, now: 5 * time.Minute, active: false},
+		{name: "cron, next day", window: cron, now: 24*time.Hour + time.Minute, active: true},
+		{name: "cron, saturday", window: cron, now: 5*24*time.Hour + time.Minute, active: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			now := base.Add(tt.now)
+
+			s := Scenario{
+				Rules: []Rule{{Window: tt.window, Action: Action{Codes: "14"}}},
+				Now:   func() time.Time { return now },
+			}
+			if err := s.Validate(); err != nil {
+				t.Fatalf("test: %s, Validate error:%v", tt.name, err)
+			}
+
+			// evaluate twice, so the cached cron result is also checked
+			for i := 0; i < 2; i++ {
+				d := s.Evaluate(echoMethod, nil, nil)
+				if d.Matched != tt.active {
+					t.Errorf("test: %s, i:%d matched:%t != active:%t", tt.name, i, d.Matched, tt.active)
+				}
+			}
+		})
+	}
+}
+
+// go test -run TestWindowFallThrough -v
+func TestWindowFallThrough(t *testing.T) {
+
+	base := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+	now := base
+
+	s := Scenario{
+		Rules: []Rule{
+			{
+				Name:   "outage",
+				Window: Window{Start: base.Format(time.RFC3339), Duration: Duration(5 * time.Minute)},
+				Action: Action{Codes: "14"},
+			},
+			{
+				Name:   "background",
+				Action: Action{Codes: "10"},
+			},
+		},
+		Now: func() time.Time { return now },
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := s.Evaluate(echoMethod, nil, nil); d.Rule != "outage" || d.Code != 14 {
+		t.Errorf("during the window, rule:%q code:%d", d.Rule, d.Code)
+	}
+
+	now = base.Add(5 * time.Minute)
+
+	if d := s.Evaluate(echoMethod, nil, nil); d.Rule != "background" || d.Code != 10 {
+		t.Errorf("after the window, rule:%q code:%d", d.Rule, d.Code)
+	}
+}
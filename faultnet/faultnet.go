@@ -0,0 +1,118 @@
+package faultnet
+
+// faultnet injects network faults below gRPC, by wrapping net.Listener and net.Conn
+// e.g. connection resets, stalls, and slow links
+//
+// The server wraps the listener, in place of net.Listen
+//   lis, err := faultnet.Listen("tcp", ":50052", config)
+// The client wraps the connections, with grpc.WithContextDialer
+//   grpc.WithContextDialer(faultnet.Dialer(config))
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+// maxJitter is the largest Jitter, because the random jitter is a uint32 of nanoseconds
+const maxJitter = time.Duration(1<<32 - 1)
+
+var (
+	errLatency     = errors.New("faultnet Latency and Jitter must not be negative")
+	errJitter      = errors.New("faultnet Jitter must not be more than 4.294967295s")
+	errBandwidth   = errors.New("faultnet Bandwidth must not be negative")
+	errAfterBytes  = errors.New("faultnet AfterBytes must not be negative")
+	errAfter       = errors.New("faultnet After must not be negative")
+	errAcceptFail  = errors.New("faultnet AcceptFailPercent must be 0-100")
+	errInterrupted = errors.New("faultnet connection interrupted")
+)
+
+// Config is the network faults for each connection
+// Latency, plus a random Jitter up to Jitter, is added before each Write.  Jitter is at most 4.294967295s
+// Bandwidth limits each connection to this many bytes per second, in each direction
+// AfterBytes closes the connection after this many bytes, read plus written
+// After closes the connection after this duration
+// Reset closes with a TCP RST, rather than a FIN, after AfterBytes or After
+// AcceptFailPercent of the accepted connections are reset immediately
+// Zero (0) is disabled
+type Config struct {
+	Latency           time.Duration
+	Jitter            time.Duration
+	Bandwidth         int64
+	AfterBytes        int64
+	After             time.Duration
+	Reset             bool
+	AcceptFailPercent int
+}
+
+// Validate checks the config
+func (c Config) Validate() error {
+
+	if c.Latency < 0 || c.Jitter < 0 {
+		return errLatency
+	}
+
+	if c.Jitter > maxJitter {
+		return errJitter
+	}
+
+	if c.Bandwidth < 0 {
+		return errBandwidth
+	}
+
+	if c.AfterBytes < 0 {
+		return errAfterBytes
+	}
+
+	if c.After < 0 {
+		return errAfter
+	}
+
+	if c.AcceptFailPercent != 0 {
+		if _, err := validate.ValidatePercent(int64(c.AcceptFailPercent)); err != nil {
+			return errAcceptFail
+		}
+	}
+
+	return nil
+}
+
+// Enabled is true if any fault is configured
+func (c Config) Enabled() bool {
+	return c != Config{}
+}
+
+var (
+	accepted       atomic.Uint64
+	acceptFailures atomic.Uint64
+	dialed         atomic.Uint64
+	resets         atomic.Uint64
+	closes         atomic.Uint64
+	drains         atomic.Uint64
+)
+
+// Stats are the faultnet counters, which are shared by all the listeners and dialers
+// Resets and Closes are the connections interrupted after AfterBytes or After, or by the Server
+// Drains is the number of Server Drains
+type Stats struct {
+	Accepted       uint64
+	AcceptFailures uint64
+	Dialed         uint64
+	Resets         uint64
+	Closes         uint64
+	Drains         uint64
+}
+
+// GetStats returns a snapshot of the counters
+func GetStats() Stats {
+	return Stats{
+		Accepted:       accepted.Load(),
+		AcceptFailures: acceptFailures.Load(),
+		Dialed:         dialed.Load(),
+		Resets:         resets.Load(),
+		Closes:         closes.Load(),
+		Drains:         drains.Load(),
+	}
+}
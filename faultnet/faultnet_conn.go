@@ -0,0 +1,190 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+)
+
+// conn is a net.Conn with network faults
+type conn struct {
+	net.Conn
+	config Config
+
+	// bytes is the total read plus written
+	bytes atomic.Int64
+
+	once        sync.Once
+	interrupted atomic.Bool
+	timer       *time.Timer
+}
+
+// NewConn wraps the connection with the network faults
+// The After timer starts now
+func NewConn(c net.Conn, config Config) net.Conn {
+
+	if !config.Enabled() {
+		return c
+	}
+
+	fc := &conn{
+		Conn:   c,
+		config: config,
+	}
+
+	if config.After > 0 {
+		fc.timer = time.AfterFunc(config.After, fc.interrupt)
+	}
+
+	return fc
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+
+	if c.interrupted.Load() {
+		return 0, errInterrupted
+	}
+
+	n, err := c.Conn.Read(c.limit(b))
+	c.count(n)
+
+	return n, err
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+
+	if c.interrupted.Load() {
+		return 0, errInterrupted
+	}
+
+	if d := c.latency(); d > 0 {
+		time.Sleep(d)
+	}
+
+	// the writes are limited by AfterBytes, and the bandwidth, so write in chunks
+	var written int
+	for written < len(b) {
+
+		if c.interrupted.Load() {
+			return written, errInterrupted
+		}
+
+		n, err := c.Conn.Write(c.limit(b[written:]))
+		c.count(n)
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+func (c *conn) Close() error {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	return c.Conn.Close()
+}
+
+// latency is the Latency, plus a random jitter
+func (c *conn) latency() time.Duration {
+	d := c.config.Latency
+	if c.config.Jitter > 0 {
+		d += time.Duration(rand.FastRandN(uint32(min(c.config.Jitter, maxJitter))))
+	}
+	return d
+}
+
+// limit shortens the buffer, so the AfterBytes is exact, and the bandwidth is smooth
+func (c *conn) limit(b []byte) []byte {
+
+	if c.config.AfterBytes > 0 {
+		left := c.config.AfterBytes - c.bytes.Load()
+		if left <= 0 {
+			left = 1
+		}
+		if int64(len(b)) > left {
+			b = b[:left]
+		}
+	}
+
+	// limit to 1/10th of a second of bandwidth
+	if c.config.Bandwidth > 0 {
+		chunk := max(c.config.Bandwidth/10, 1)
+		if int64(len(b)) > chunk {
+			b = b[:chunk]
+		}
+	}
+
+	return b
+}
+
+// count adds the bytes, sleeps for the bandwidth, and interrupts after AfterBytes
+func (c *conn) count(n int) {
+
+	if n <= 0 {
+		return
+	}
+
+	total := c.bytes.Add(int64(n))
+
+	if c.config.Bandwidth > 0 {
+		time.Sleep(time.Duration(int64(n) * int64(time.Second) / c.config.Bandwidth))
+	}
+
+	if c.config.AfterBytes > 0 && total >= c.config.AfterBytes {
+		c.interrupt()
+	}
+}
+
+// interrupt closes the connection, with a RST if Reset is set
+func (c *conn) interrupt() {
+	c.once.Do(func() {
+
+		c.interrupted.Store(true)
+
+		if c.config.Reset {
+			resets.Add(1)
+			reset(c.Conn)
+			return
+		}
+
+		closes.Add(1)
+		c.Conn.Close()
+	})
+}
+
+// NetConn returns the underlying connection
+func (c *conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// reset closes the connection with a TCP RST, rather than a FIN
+func reset(c net.Conn) {
+	resetConn(c)
+	c.Close()
+}
+
+// resetConn sets the TCP connection to send a RST on close
+// SetLinger(0) discards any unsent data, and sends a RST on close
+// Wrapped connections are unwrapped with NetConn, e.g. a faultnet conn, or a tls.Conn
+func resetConn(c net.Conn) {
+	for {
+		switch cc := c.(type) {
+		case *net.TCPConn:
+			cc.SetLinger(0)
+			return
+		case interface{ NetConn() net.Conn }:
+			c = cc.NetConn()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,148 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type connTest struct {
+	name    string
+	config  Config
+	write   int
+	peerGot int
+	reset   bool
+	minTime time.Duration
+}
+
+// go test -run TestConn -v
+func TestConn(t *testing.T) {
+	tests := []connTest{
+		{
+			name:    "no faults",
+			config:  Config{},
+			write:   1000,
+			peerGot: 1000,
+		},
+		{
+			name:    "close after 100 bytes",
+			config:  Config{AfterBytes: 100},
+			write:   1000,
+			peerGot: 100,
+		},
+		{
+			name:    "reset after 100 bytes",
+			config:  Config{AfterBytes: 100, Reset: true},
+			write:   1000,
+			peerGot: 100,
+			reset:   true,
+		},
+		{
+			name:    "bandwidth 10000 bytes per second",
+			config:  Config{Bandwidth: 10000},
+			write:   2000,
+			peerGot: 2000,
+			minTime: 200 * time.Millisecond,
+		},
+		{
+			name:    "latency 50ms",
+			config:  Config{Latency: 50 * time.Millisecond},
+			write:   10,
+			peerGot: 10,
+			minTime: 50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			c, peer := tcpPair(t)
+			fc := NewConn(c, tt.config)
+
+			start := time.Now()
+
+			go func() {
+				fc.Write(make([]byte, tt.write))
+				fc.Close()
+			}()
+
+			got, err := io.Copy(io.Discard, peer)
+			elapsed := time.Since(start)
+
+			if int(got) != tt.peerGot {
+				t.Errorf("test: %s, peer got:%d != tt.peerGot:%d", tt.name, got, tt.peerGot)
+			}
+			if isReset(err) != tt.reset {
+				t.Errorf("test: %s, reset:%t != tt.reset:%t, err:%v", tt.name, isReset(err), tt.reset, err)
+			}
+			if elapsed < tt.minTime {
+				t.Errorf("test: %s, elapsed:%s < tt.minTime:%s", tt.name, elapsed, tt.minTime)
+			}
+		})
+	}
+}
+
+// go test -run TestConnAfter -v
+func TestConnAfter(t *testing.T) {
+
+	c, peer := tcpPair(t)
+	before := GetStats()
+
+	fc := NewConn(c, Config{After: 50 * time.Millisecond, Reset: true})
+	defer fc.Close()
+
+	_, err := io.Copy(io.Discard, peer)
+	if !isReset(err) {
+		t.Errorf("err:%v is not a reset", err)
+	}
+
+	if _, err := fc.Write([]byte("x")); !errors.Is(err, errInterrupted) {
+		t.Errorf("write after interrupt err:%v != errInterrupted", err)
+	}
+
+	if r := GetStats().Resets - before.Resets; r != 1 {
+		t.Errorf("resets:%d != 1", r)
+	}
+}
+
+// tcpPair returns both ends of a loopback TCP connection
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, _ := l.Accept()
+		accepted <- c
+	}()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer := <-accepted
+	if peer == nil {
+		t.Fatal("accept failed")
+	}
+
+	t.Cleanup(func() {
+		c.Close()
+		peer.Close()
+	})
+
+	return c, peer
+}
+
+func isReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET)
+}
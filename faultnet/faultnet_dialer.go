@@ -0,0 +1,29 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"context"
+	"net"
+)
+
+// Dialer returns a dial function for grpc.WithContextDialer, which wraps the
+// connections with the network faults
+// The config must be valid, see Validate
+// e.g. grpc.WithContextDialer(faultnet.Dialer(config))
+func Dialer(config Config) func(ctx context.Context, address string) (net.Conn, error) {
+
+	var d net.Dialer
+
+	return func(ctx context.Context, address string) (net.Conn, error) {
+
+		c, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, err
+		}
+
+		dialed.Add(1)
+
+		return NewConn(c, config), nil
+	}
+}
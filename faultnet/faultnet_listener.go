@@ -0,0 +1,65 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"net"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+)
+
+// listener is a net.Listener, which wraps the accepted connections with the network faults
+type listener struct {
+	net.Listener
+	config Config
+}
+
+// Listen is net.Listen, with the network faults
+func Listen(network, address string, config Config) (net.Listener, error) {
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewListener(l, config), nil
+}
+
+// NewListener wraps the listener with the network faults
+// The config must be valid, see Validate
+func NewListener(l net.Listener, config Config) net.Listener {
+
+	if !config.Enabled() {
+		return l
+	}
+
+	return &listener{
+		Listener: l,
+		config:   config,
+	}
+}
+
+// Accept resets AcceptFailPercent of the connections immediately,
+// and waits for the next connection
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.config.AcceptFailPercent > 0 && rand.SamplePercent(l.config.AcceptFailPercent) {
+			acceptFailures.Add(1)
+			reset(c)
+			continue
+		}
+
+		accepted.Add(1)
+
+		return NewConn(c, l.config), nil
+	}
+}
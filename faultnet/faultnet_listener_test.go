@@ -0,0 +1,78 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+type listenerTest struct {
+	name     string
+	config   Config
+	accepted uint64
+	failures uint64
+}
+
+// go test -run TestListener -v
+func TestListener(t *testing.T) {
+	tests := []listenerTest{
+		{
+			name:     "accepted",
+			config:   Config{Latency: time.Millisecond},
+			accepted: 1,
+		},
+		{
+			name:     "accept fail 100%",
+			config:   Config{AcceptFailPercent: 100},
+			failures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			l, err := Listen("tcp", "127.0.0.1:0", tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			before := GetStats()
+
+			go func() {
+				c, err := l.Accept()
+				if err != nil {
+					return
+				}
+				c.Write([]byte("hello"))
+				c.Close()
+			}()
+
+			// the reset can arrive before the dial returns
+			var got []byte
+			c, err := net.Dial("tcp", l.Addr().String())
+			if err == nil {
+				defer c.Close()
+				got, err = io.ReadAll(c)
+			}
+			if tt.failures > 0 {
+				if !isReset(err) {
+					t.Errorf("test: %s, err:%v is not a reset", tt.name, err)
+				}
+			} else if string(got) != "hello" {
+				t.Errorf("test: %s, got:%q != hello, err:%v", tt.name, got, err)
+			}
+
+			after := GetStats()
+			if a := after.Accepted - before.Accepted; a != tt.accepted {
+				t.Errorf("test: %s, accepted:%d != tt.accepted:%d", tt.name, a, tt.accepted)
+			}
+			if f := after.AcceptFailures - before.AcceptFailures; f != tt.failures {
+				t.Errorf("test: %s, failures:%d != tt.failures:%d", tt.name, f, tt.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+//go:build nofaultinjection
+
+package faultnet
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// The listeners and connections are not wrapped, so there are no network faults
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// NewConn returns the connection unchanged
+func NewConn(c net.Conn, config Config) net.Conn {
+	return c
+}
+
+// Listen is net.Listen, and the config is ignored
+func Listen(network, address string, config Config) (net.Listener, error) {
+	return net.Listen(network, address)
+}
+
+// NewListener returns the listener unchanged
+func NewListener(l net.Listener, config Config) net.Listener {
+	return l
+}
+
+// Dialer returns a plain TCP dial function, for grpc.WithContextDialer
+func Dialer(config Config) func(ctx context.Context, address string) (net.Conn, error) {
+
+	var d net.Dialer
+
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", address)
+	}
+}
+
+// Server serves a grpc.Server, and the connection faults do nothing
+type Server struct {
+	lis       net.Listener
+	newServer func() *grpc.Server
+
+	once sync.Once
+	srv  *grpc.Server
+}
+
+// NewServer returns a Server, which serves on the listener
+func NewServer(lis net.Listener, newServer func() *grpc.Server) *Server {
+	return &Server{
+		lis:       lis,
+		newServer: newServer,
+	}
+}
+
+func (s *Server) server() *grpc.Server {
+	s.once.Do(func() {
+		s.srv = s.newServer()
+	})
+	return s.srv
+}
+
+// Serve serves the grpc.Server on the listener
+func (s *Server) Serve() error {
+	return s.server().Serve(s.lis)
+}
+
+// Drain does nothing, fault injection is not compiled in
+func (s *Server) Drain() {}
+
+// CloseConn does nothing, and returns false
+func (s *Server) CloseConn(addr net.Addr, reset bool) bool {
+	return false
+}
+
+// CloseAll does nothing, fault injection is not compiled in
+func (s *Server) CloseAll(reset bool) {}
+
+// Schedule returns immediately, fault injection is not compiled in
+func (s *Server) Schedule(ctx context.Context, interval time.Duration, action func()) {}
+
+// GracefulStop gracefully stops the grpc.Server
+func (s *Server) GracefulStop() {
+	s.server().GracefulStop()
+}
+
+// Stop stops the grpc.Server
+func (s *Server) Stop() {
+	s.server().Stop()
+}
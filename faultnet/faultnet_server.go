@@ -0,0 +1,274 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	errServing = errors.New("faultnet Server is already serving")
+)
+
+// Server serves a grpc.Server, and can close the connections, or drain the server, mid traffic
+// so the client reconnect, retry, and load balancing can be tested
+// Drain starts a new grpc.Server, and gracefully stops the old one, so the clients get a GOAWAY,
+// the requests in flight complete, and the clients reconnect to the new grpc.Server
+// The newServer function creates each grpc.Server, with the services registered
+type Server struct {
+	lis       net.Listener
+	newServer func() *grpc.Server
+
+	mu      sync.Mutex
+	current *grpc.Server
+	child   *childListener
+	stopped bool
+	serving bool
+
+	// conns are the open connections, keyed by the remote address
+	conns sync.Map
+}
+
+// NewServer returns a Server, which serves on the listener
+// The listener can be a faultnet listener, so the network faults also apply
+func NewServer(lis net.Listener, newServer func() *grpc.Server) *Server {
+	return &Server{
+		lis:       lis,
+		newServer: newServer,
+	}
+}
+
+// Serve accepts the connections, and serves them on the current grpc.Server
+// Serve returns when the listener fails, or Stop or GracefulStop is called
+func (s *Server) Serve() error {
+
+	s.mu.Lock()
+	if s.serving {
+		s.mu.Unlock()
+		return errServing
+	}
+	s.serving = true
+	s.mu.Unlock()
+
+	s.next()
+
+	for {
+		c, err := s.lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			stopped := s.stopped
+			s.mu.Unlock()
+			if stopped {
+				return nil
+			}
+			return err
+		}
+
+		tc := &trackedConn{Conn: c, s: s}
+		s.conns.Store(c.RemoteAddr().String(), tc)
+
+		s.dispatch(tc)
+	}
+}
+
+// dispatch hands the connection to the current grpc.Server
+func (s *Server) dispatch(c net.Conn) {
+	for {
+		s.mu.Lock()
+		child, stopped := s.child, s.stopped
+		s.mu.Unlock()
+
+		if stopped {
+			c.Close()
+			return
+		}
+
+		select {
+		case child.conns <- c:
+			return
+		case <-child.done:
+			// a Drain replaced the grpc.Server, so try the new one
+		}
+	}
+}
+
+// next starts a new grpc.Server, and returns the old one
+func (s *Server) next() *grpc.Server {
+
+	srv := s.newServer()
+	child := newChildListener(s.lis.Addr())
+
+	s.mu.Lock()
+	old := s.current
+	s.current, s.child = srv, child
+	s.mu.Unlock()
+
+	go srv.Serve(child)
+
+	return old
+}
+
+// Drain starts a new grpc.Server, and gracefully stops the old one
+// The old grpc.Server sends a GOAWAY to its clients, and waits for the requests in flight
+// Drain returns once the new grpc.Server is serving, and the old one drains in the background
+func (s *Server) Drain() {
+
+	s.mu.Lock()
+	if s.stopped || !s.serving {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	drains.Add(1)
+
+	if old := s.next(); old != nil {
+		go old.GracefulStop()
+	}
+}
+
+// CloseConn closes the connection from the remote address, with a RST if reset is set
+// It returns false if there is no open connection from the address
+func (s *Server) CloseConn(addr net.Addr, reset bool) bool {
+
+	if addr == nil {
+		return false
+	}
+
+	v, ok := s.conns.Load(addr.String())
+	if !ok {
+		return false
+	}
+
+	c := v.(*trackedConn)
+	if reset {
+		resets.Add(1)
+		resetConn(c.Conn)
+	} else {
+		closes.Add(1)
+	}
+	c.Close()
+
+	return true
+}
+
+// CloseAll closes all the connections, with a RST if reset is set
+func (s *Server) CloseAll(reset bool) {
+	s.conns.Range(func(_, v any) bool {
+		c := v.(*trackedConn)
+		if reset {
+			resets.Add(1)
+			resetConn(c.Conn)
+		} else {
+			closes.Add(1)
+		}
+		c.Close()
+		return true
+	})
+}
+
+// Schedule runs the action every interval, until the context is done
+// e.g. go s.Schedule(ctx, time.Minute, s.Drain)
+func (s *Server) Schedule(ctx context.Context, interval time.Duration, action func()) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			action()
+		}
+	}
+}
+
+// GracefulStop stops accepting connections, and gracefully stops the grpc.Server
+func (s *Server) GracefulStop() {
+	if srv := s.stop(); srv != nil {
+		srv.GracefulStop()
+	}
+}
+
+// Stop stops accepting connections, and stops the grpc.Server
+func (s *Server) Stop() {
+	if srv := s.stop(); srv != nil {
+		srv.Stop()
+	}
+}
+
+func (s *Server) stop() *grpc.Server {
+
+	s.mu.Lock()
+	s.stopped = true
+	srv := s.current
+	s.mu.Unlock()
+
+	s.lis.Close()
+
+	return srv
+}
+
+// trackedConn removes itself from the open connections when closed
+type trackedConn struct {
+	net.Conn
+	s    *Server
+	once sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.s.conns.Delete(c.RemoteAddr().String())
+	})
+	return c.Conn.Close()
+}
+
+// NetConn returns the underlying connection
+func (c *trackedConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// childListener is the listener of each grpc.Server, which is fed by Server.Serve
+// Closing the childListener doesn't close the real listener, so a new grpc.Server
+// can keep serving while the old one drains
+type childListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newChildListener(addr net.Addr) *childListener {
+	return &childListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *childListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *childListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *childListener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,107 @@
+//go:build !nofaultinjection
+
+package faultnet
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/examples/features/proto/echo"
+)
+
+type echoServer struct {
+	echo.UnimplementedEchoServer
+}
+
+func (echoServer) UnaryEcho(_ context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	return &echo.EchoResponse{Message: req.Message}, nil
+}
+
+type serverTest struct {
+	name   string
+	action func(s *Server)
+	drains uint64
+}
+
+// go test -run TestServer -v
+func TestServer(t *testing.T) {
+	tests := []serverTest{
+		{
+			name:   "drain",
+			action: func(s *Server) { s.Drain() },
+			drains: 1,
+		},
+		{
+			name:   "close all",
+			action: func(s *Server) { s.CloseAll(false) },
+		},
+		{
+			name:   "reset all",
+			action: func(s *Server) { s.CloseAll(true) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var servers int
+			s := NewServer(lis, func() *grpc.Server {
+				servers++
+				srv := grpc.NewServer()
+				echo.RegisterEchoServer(srv, echoServer{})
+				return srv
+			})
+			go s.Serve()
+			defer s.Stop()
+
+			cc, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cc.Close()
+			client := echo.NewEchoClient(cc)
+
+			before := GetStats()
+
+			call(t, client)
+			tt.action(s)
+
+			// the client reconnects, which can take a few attempts after a close
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				_, err := client.UnaryEcho(ctx, &echo.EchoRequest{Message: "after"}, grpc.WaitForReady(true))
+				if err == nil {
+					break
+				}
+				if ctx.Err() != nil {
+					t.Fatalf("test: %s, no request succeeded after the action, err:%v", tt.name, err)
+				}
+			}
+
+			if d := GetStats().Drains - before.Drains; d != tt.drains {
+				t.Errorf("test: %s, drains:%d != tt.drains:%d", tt.name, d, tt.drains)
+			}
+			if want := 1 + int(tt.drains); servers != want {
+				t.Errorf("test: %s, servers:%d != %d", tt.name, servers, want)
+			}
+		})
+	}
+}
+
+func call(t *testing.T, client echo.EchoClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.UnaryEcho(ctx, &echo.EchoRequest{Message: "before"}); err != nil {
+		t.Fatalf("UnaryEcho error:%v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package faultnet
+
+import (
+	"testing"
+	"time"
+)
+
+type validateTest struct {
+	name      string
+	config    Config
+	expectErr bool
+}
+
+// go test -run TestValidate -v
+func TestValidate(t *testing.T) {
+	tests := []validateTest{
+		{name: "valid, empty", config: Config{}},
+		{name: "valid, all", config: Config{Latency: time.Millisecond, Jitter: time.Millisecond, Bandwidth: 1000,
+			AfterBytes: 100, After: time.Second, Reset: true, AcceptFailPercent: 100}},
+		{name: "invalid, negative latency", config: Config{Latency: -1}, expectErr: true},
+		{name: "valid, max jitter", config: Config{Jitter: maxJitter}},
+		{name: "invalid, jitter above the max", config: Config{Jitter: maxJitter + 1}, expectErr: true},
+		{name: "invalid, negative bandwidth", config: Config{Bandwidth: -1}, expectErr: true},
+		{name: "invalid, negative after bytes", config: Config{AfterBytes: -1}, expectErr: true},
+		{name: "invalid, negative after", config: Config{After: -1}, expectErr: true},
+		{name: "invalid, accept fail 101", config: Config{AcceptFailPercent: 101}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ go 1.23.1
 require (
 	google.golang.org/grpc v1.68.0
 	google.golang.org/grpc/examples v0.0.0-20241108060052-a3a865707898
+	google.golang.org/protobuf v1.35.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -12,5 +14,4 @@ require (
 	golang.org/x/sys v0.27.0 // indirect
 	golang.org/x/text v0.20.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241104194629-dd2ea8efbc28 // indirect
-	google.golang.org/protobuf v1.35.1 // indirect
 )
@@ -0,0 +1,170 @@
+package budget
+
+// This .go file holds the fault budgets, which limit the number of injected faults
+// so a misconfigured "faultpercent: 100" can't take down a shared server
+//
+// There are three optional limits
+// - a hard cap on the total number of faults
+// - a token bucket, limiting the faults per second
+// - a cap on the total number of faults per caller
+//
+// Once a limit is reached, Allow returns false, and the request is not faulted
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/counters"
+)
+
+// Reason is why a fault was not allowed
+type Reason int32
+
+const (
+	// Allowed means the fault is within the budget
+	Allowed Reason = iota
+	// Total means the MaxFaults has been reached
+	Total Reason = 1
+	// Rate means the FaultsPerSecond token bucket is empty
+	Rate Reason = 2
+	// Caller means the MaxFaultsPerCaller has been reached for the caller
+	Caller Reason = 3
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Allowed:
+		return "allowed"
+	case Total:
+		return "total"
+	case Rate:
+		return "rate"
+	case Caller:
+		return "caller"
+	default:
+		return "invalid"
+	}
+}
+
+// Config is the budget configuration.  Zero (0) is unlimited
+// Burst is the token bucket size, and zero (0) is the FaultsPerSecond rounded up
+// MaxCallers and CallerTTL bound the memory of the per caller counts, like counters.NewKeyed
+// A caller evicted by MaxCallers, or unused for the CallerTTL, has its MaxFaultsPerCaller count reset,
+// so MaxFaultsPerCaller is per caller, per CallerTTL, and a caller cycling identities gets a new budget.
+// Use MaxFaults, or FaultsPerSecond, to limit all the callers
+type Config struct {
+	MaxFaults          uint64
+	FaultsPerSecond    float64
+	Burst              int
+	MaxFaultsPerCaller uint64
+	MaxCallers         int
+	CallerTTL          time.Duration
+}
+
+// Budget tracks the faults against the Config
+type Budget struct {
+	config Config
+
+	mu     sync.Mutex
+	faults uint64
+	tokens float64
+	last   time.Time
+
+	callers *counters.Keyed
+
+	// now is time.Now, and can be replaced in tests
+	now func() time.Time
+}
+
+// New returns a Budget, or nil if the Config has no limits
+func New(c Config) *Budget {
+
+	if c.MaxFaults == 0 && c.FaultsPerSecond <= 0 && c.MaxFaultsPerCaller == 0 {
+		return nil
+	}
+
+	if c.FaultsPerSecond > 0 && c.Burst <= 0 {
+		c.Burst = int(math.Ceil(c.FaultsPerSecond))
+	}
+
+	b := &Budget{
+		config: c,
+		tokens: float64(c.Burst),
+		now:    time.Now,
+	}
+
+	if c.MaxFaultsPerCaller > 0 {
+		b.callers = counters.NewKeyed(c.MaxCallers, c.CallerTTL)
+	}
+
+	return b
+}
+
+// Allow returns Allowed, and uses the budget, if the fault is within all the limits
+// Otherwise the budget is not used, and the first limit reached is returned
+// A nil Budget allows every fault
+func (b *Budget) Allow(caller string) Reason {
+
+	if b == nil {
+		return Allowed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.config.MaxFaults > 0 && b.faults >= b.config.MaxFaults {
+		return Total
+	}
+
+	if b.config.FaultsPerSecond > 0 {
+		b.refillLocked()
+		if b.tokens < 1 {
+			return Rate
+		}
+	}
+
+	if b.callers != nil {
+		c := b.callers.Counter(caller)
+		if c.Load() >= b.config.MaxFaultsPerCaller {
+			return Caller
+		}
+		c.Add(1)
+	}
+
+	if b.config.FaultsPerSecond > 0 {
+		b.tokens--
+	}
+
+	b.faults++
+
+	return Allowed
+}
+
+// refillLocked adds the tokens for the time since the last refill.  b.mu must be held
+func (b *Budget) refillLocked() {
+
+	now := b.now()
+
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.config.FaultsPerSecond
+		if b.tokens > float64(b.config.Burst) {
+			b.tokens = float64(b.config.Burst)
+		}
+	}
+
+	b.last = now
+}
+
+// Faults returns the number of faults allowed
+func (b *Budget) Faults() uint64 {
+
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.faults
+}
@@ -0,0 +1,121 @@
+package budget
+
+import (
+	"testing"
+	"time"
+)
+
+type allowTest struct {
+	name    string
+	config  Config
+	callers []string
+	advance time.Duration
+	reasons []Reason
+}
+
+// go test -run TestAllow -v
+func TestAllow(t *testing.T) {
+	tests := []allowTest{
+		{
+			name:    "max faults 2",
+			config:  Config{MaxFaults: 2},
+			callers: []string{"a", "a", "a", "b"},
+			reasons: []Reason{Allowed, Allowed, Total, Total},
+		},
+		{
+			name:    "2 per second, no time passes",
+			config:  Config{FaultsPerSecond: 2},
+			callers: []string{"a", "a", "a"},
+			reasons: []Reason{Allowed, Allowed, Rate},
+		},
+		{
+			name:    "2 per second, 250ms between faults",
+			config:  Config{FaultsPerSecond: 2},
+			callers: []string{"a", "a", "a", "a", "a", "a"},
+			advance: 250 * time.Millisecond,
+			reasons: []Reason{Allowed, Allowed, Allowed, Rate, Allowed, Rate},
+		},
+		{
+			name:    "burst 1",
+			config:  Config{FaultsPerSecond: 100, Burst: 1},
+			callers: []string{"a", "a"},
+			reasons: []Reason{Allowed, Rate},
+		},
+		{
+			name:    "1 per caller",
+			config:  Config{MaxFaultsPerCaller: 1},
+			callers: []string{"a", "b", "a", "c", "b"},
+			reasons: []Reason{Allowed, Allowed, Caller, Allowed, Caller},
+		},
+		{
+			name:    "caller cap doesn't use the total",
+			config:  Config{MaxFaults: 2, MaxFaultsPerCaller: 1},
+			callers: []string{"a", "a", "b", "c"},
+			reasons: []Reason{Allowed, Caller, Allowed, Total},
+		},
+		{
+			name:    "1 per caller, an evicted caller is reset",
+			config:  Config{MaxFaultsPerCaller: 1, MaxCallers: 1},
+			callers: []string{"a", "a", "b", "a", "a"},
+			reasons: []Reason{Allowed, Caller, Allowed, Allowed, Caller},
+		},
+		{
+			name:    "1 per caller, 2 callers kept",
+			config:  Config{MaxFaultsPerCaller: 1, MaxCallers: 2},
+			callers: []string{"a", "b", "a", "b"},
+			reasons: []Reason{Allowed, Allowed, Caller, Caller},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			now := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+
+			b := New(tt.config)
+			b.now = func() time.Time { return now }
+
+			for i, caller := range tt.callers {
+				if r := b.Allow(caller); r != tt.reasons[i] {
+					t.Errorf("test: %s, i:%d caller:%s reason:%s != %s", tt.name, i, caller, r, tt.reasons[i])
+				}
+				now = now.Add(tt.advance)
+			}
+		})
+	}
+}
+
+// go test -run TestNilBudget -v
+func TestNilBudget(t *testing.T) {
+
+	b := New(Config{})
+	if b != nil {
+		t.Fatal("New(Config{}) != nil")
+	}
+
+	for i := 0; i < 10; i++ {
+		if r := b.Allow("a"); r != Allowed {
+			t.Errorf("i:%d reason:%s != allowed", i, r)
+		}
+	}
+}
+
+// go test -run TestCallerTTL -v
+func TestCallerTTL(t *testing.T) {
+
+	b := New(Config{MaxFaultsPerCaller: 1, CallerTTL: time.Millisecond})
+
+	if r := b.Allow("a"); r != Allowed {
+		t.Errorf("first reason:%s != %s", r, Allowed)
+	}
+	if r := b.Allow("a"); r != Caller {
+		t.Errorf("second reason:%s != %s", r, Caller)
+	}
+
+	// the caller is unused for the CallerTTL, so the count is reset
+	time.Sleep(5 * time.Millisecond)
+
+	if r := b.Allow("a"); r != Allowed {
+		t.Errorf("after the CallerTTL reason:%s != %s", r, Allowed)
+	}
+}
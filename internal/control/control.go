@@ -0,0 +1,123 @@
+package control
+
+// This .go file holds the kill switch and the dry run ( shadow ) mode
+// so the interceptors can be deployed everywhere, and controlled centrally
+//
+// The initial state is read from the GRPC_FAULT_INJECTION environment variable
+// "on" ( or unset ) injects faults, "off" disables the interceptor, and "dryrun"
+// logs and counts the faults which would have been injected, but doesn't inject them
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// EnvVar is the environment variable read by FromEnv
+	EnvVar = "GRPC_FAULT_INJECTION"
+
+	envOn     = "on"
+	envOff    = "off"
+	envDryRun = "dryrun"
+)
+
+var (
+	errEnv = errors.New(EnvVar + " must be on, off, or dryrun")
+)
+
+// Switch is the kill switch and dry run state, which is safe for concurrent use
+// The zero value is enabled, and not dry run
+type Switch struct {
+	disabled atomic.Bool
+	dryRun   atomic.Bool
+}
+
+// FromEnv returns a Switch set from the GRPC_FAULT_INJECTION environment variable
+// An invalid value returns an error, and a disabled Switch, so faults are not
+// injected by mistake
+func FromEnv() (*Switch, error) {
+	s := new(Switch)
+	err := s.Set(os.Getenv(EnvVar))
+	if err != nil {
+		s.Disable()
+	}
+	return s, err
+}
+
+// Set sets the state from a string, which is "on", "off", or "dryrun"
+// Empty is "on"
+func (s *Switch) Set(str string) error {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "", envOn:
+		s.Enable()
+		s.SetDryRun(false)
+	case envOff:
+		s.Disable()
+		s.SetDryRun(false)
+	case envDryRun:
+		s.Enable()
+		s.SetDryRun(true)
+	default:
+		return errEnv
+	}
+	return nil
+}
+
+// String is the state, "on", "off", or "dryrun"
+func (s *Switch) String() string {
+	switch {
+	case !s.Enabled():
+		return envOff
+	case s.DryRun():
+		return envDryRun
+	default:
+		return envOn
+	}
+}
+
+// Enable enables fault injection
+func (s *Switch) Enable() {
+	s.disabled.Store(false)
+}
+
+// Disable disables fault injection, so the interceptor passes every request through
+func (s *Switch) Disable() {
+	s.disabled.Store(true)
+}
+
+// Enabled is checked on every request
+func (s *Switch) Enabled() bool {
+	return !s.disabled.Load()
+}
+
+// Toggle switches between enabled and disabled, and returns true if now enabled
+func (s *Switch) Toggle() bool {
+	for {
+		d := s.disabled.Load()
+		if s.disabled.CompareAndSwap(d, !d) {
+			return d
+		}
+	}
+}
+
+// SetDryRun sets the dry run mode
+func (s *Switch) SetDryRun(dryRun bool) {
+	s.dryRun.Store(dryRun)
+}
+
+// DryRun is true if faults should be logged and counted, but not injected
+func (s *Switch) DryRun() bool {
+	return s.dryRun.Load()
+}
+
+// ToggleDryRun switches the dry run mode, and returns true if now dry run
+func (s *Switch) ToggleDryRun() bool {
+	for {
+		d := s.dryRun.Load()
+		if s.dryRun.CompareAndSwap(d, !d) {
+			return !d
+		}
+	}
+}
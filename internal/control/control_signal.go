@@ -0,0 +1,36 @@
+//go:build !windows
+
+package control
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals toggles the Switch on signals, until the context is done
+// SIGUSR1 toggles enabled / disabled, and SIGUSR2 toggles dry run
+// e.g. kill -USR1 <pid>
+func (s *Switch) HandleSignals(ctx context.Context, logger *log.Logger) {
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			switch sig {
+			case syscall.SIGUSR1:
+				s.Toggle()
+			case syscall.SIGUSR2:
+				s.ToggleDryRun()
+			}
+			logger.Printf("fault injection signal:%s state:%s", sig, s)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package control
+
+import (
+	"context"
+	"log"
+)
+
+// HandleSignals is not supported on windows, which doesn't have SIGUSR1 or SIGUSR2
+func (s *Switch) HandleSignals(ctx context.Context, logger *log.Logger) {
+	logger.Print("fault injection signals are not supported on windows")
+	<-ctx.Done()
+}
@@ -0,0 +1,71 @@
+package control
+
+import (
+	"testing"
+)
+
+type setTest struct {
+	str       string
+	expectErr bool
+	enabled   bool
+	dryRun    bool
+}
+
+// go test -run TestSet -v
+func TestSet(t *testing.T) {
+	tests := []setTest{
+		{str: "", enabled: true, dryRun: false},
+		{str: "on", enabled: true, dryRun: false},
+		{str: "OFF", enabled: false, dryRun: false},
+		{str: " dryrun ", enabled: true, dryRun: true},
+		{str: "blah", expectErr: true, enabled: true, dryRun: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			s := new(Switch)
+			err := s.Set(tt.str)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("str: %q, expected error: %v, got: %v", tt.str, tt.expectErr, err)
+			}
+			if s.Enabled() != tt.enabled || s.DryRun() != tt.dryRun {
+				t.Errorf("str: %q, enabled:%t dryRun:%t", tt.str, s.Enabled(), s.DryRun())
+			}
+		})
+	}
+}
+
+// go test -run TestFromEnv -v
+func TestFromEnv(t *testing.T) {
+
+	t.Setenv(EnvVar, "dryrun")
+	s, err := FromEnv()
+	if err != nil || s.String() != "dryrun" {
+		t.Errorf("dryrun, err:%v state:%s", err, s)
+	}
+
+	t.Setenv(EnvVar, "blah")
+	s, err = FromEnv()
+	if err == nil || s.Enabled() {
+		t.Errorf("invalid, err:%v state:%s", err, s)
+	}
+}
+
+// go test -run TestToggle -v
+func TestToggle(t *testing.T) {
+
+	s := new(Switch)
+
+	if s.Toggle() {
+		t.Error("first Toggle() should disable")
+	}
+	if !s.Toggle() {
+		t.Error("second Toggle() should enable")
+	}
+	if !s.ToggleDryRun() {
+		t.Error("first ToggleDryRun() should be dry run")
+	}
+	if s.String() != "dryrun" {
+		t.Errorf("state:%s != dryrun", s)
+	}
+}
@@ -0,0 +1,119 @@
+package counters
+
+// This .go file holds counters keyed by a string, e.g. method or session,
+// so that concurrent tests using different keys don't share a counter
+//
+// Memory is bounded, by evicting the least recently used key once there
+// are maxKeys, and keys not used for the ttl are reset
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultMaxKeys = 10000
+	DefaultTTL     = 10 * time.Minute
+)
+
+// Keyed is a set of counters, created on first use of each key
+type Keyed struct {
+	mu      sync.Mutex
+	m       map[string]*list.Element
+	lru     *list.List
+	maxKeys int
+	ttl     time.Duration
+
+	evictions atomic.Uint64
+
+	// now is a variable, so tests can control the time
+	now func() time.Time
+}
+
+type entry struct {
+	key      string
+	counter  *atomic.Uint64
+	lastUsed time.Time
+}
+
+// NewKeyed returns an empty set of keyed counters
+// maxKeys <= 0 uses DefaultMaxKeys, and ttl <= 0 uses DefaultTTL
+func NewKeyed(maxKeys int, ttl time.Duration) *Keyed {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Keyed{
+		m:       make(map[string]*list.Element),
+		lru:     list.New(),
+		maxKeys: maxKeys,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Add increments the counter for the key, and returns the new value
+// the first Add for a key returns 1
+func (k *Keyed) Add(key string) uint64 {
+	return k.Counter(key).Add(1)
+}
+
+// Counter returns the counter for the key, creating it if required
+// Counter is for callers that need more than Add, e.g. CompareAndSwap
+func (k *Keyed) Counter(key string) *atomic.Uint64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+
+	if e, found := k.m[key]; found {
+		ent := e.Value.(*entry)
+		if now.Sub(ent.lastUsed) > k.ttl {
+			ent.counter = new(atomic.Uint64)
+		}
+		ent.lastUsed = now
+		k.lru.MoveToFront(e)
+		return ent.counter
+	}
+
+	k.evictLocked(now)
+
+	ent := &entry{
+		key:      key,
+		counter:  new(atomic.Uint64),
+		lastUsed: now,
+	}
+	k.m[key] = k.lru.PushFront(ent)
+
+	return ent.counter
+}
+
+// evictLocked removes expired keys, and the least recently used keys
+// to make space for a new key.  k.mu must be held
+func (k *Keyed) evictLocked(now time.Time) {
+	for e := k.lru.Back(); e != nil; e = k.lru.Back() {
+		ent := e.Value.(*entry)
+		if k.lru.Len() < k.maxKeys && now.Sub(ent.lastUsed) <= k.ttl {
+			return
+		}
+		k.lru.Remove(e)
+		delete(k.m, ent.key)
+		k.evictions.Add(1)
+	}
+}
+
+// Len returns the number of keys
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lru.Len()
+}
+
+// Evictions returns the number of keys evicted
+func (k *Keyed) Evictions() uint64 {
+	return k.evictions.Load()
+}
@@ -0,0 +1,105 @@
+package counters
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// go test -run TestKeyedAdd -v
+func TestKeyedAdd(t *testing.T) {
+
+	k := NewKeyed(0, 0)
+
+	for i := uint64(1); i <= 3; i++ {
+		if c := k.Add("a"); c != i {
+			t.Errorf("TestKeyedAdd a c:%d != i:%d", c, i)
+		}
+	}
+
+	if c := k.Add("b"); c != 1 {
+		t.Errorf("TestKeyedAdd b c:%d != 1", c)
+	}
+
+	if l := k.Len(); l != 2 {
+		t.Errorf("TestKeyedAdd Len:%d != 2", l)
+	}
+}
+
+// go test -run TestKeyedAddConcurrent -v
+func TestKeyedAddConcurrent(t *testing.T) {
+
+	k := NewKeyed(0, 0)
+
+	goroutines := 10
+	adds := 1000
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < adds; i++ {
+				k.Add("key")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c := k.Add("key"); c != uint64(goroutines*adds+1) {
+		t.Errorf("TestKeyedAddConcurrent c:%d != %d", c, goroutines*adds+1)
+	}
+}
+
+// go test -run TestKeyedMaxKeys -v
+func TestKeyedMaxKeys(t *testing.T) {
+
+	k := NewKeyed(2, time.Hour)
+
+	k.Add("a")
+	k.Add("b")
+	k.Add("a") // "b" is now the least recently used
+	k.Add("c") // evicts "b"
+
+	if l := k.Len(); l != 2 {
+		t.Errorf("TestKeyedMaxKeys Len:%d != 2", l)
+	}
+	if e := k.Evictions(); e != 1 {
+		t.Errorf("TestKeyedMaxKeys Evictions:%d != 1", e)
+	}
+	if c := k.Add("a"); c != 3 {
+		t.Errorf("TestKeyedMaxKeys a c:%d != 3", c)
+	}
+	if c := k.Add("b"); c != 1 {
+		t.Errorf("TestKeyedMaxKeys b was not evicted c:%d != 1", c)
+	}
+}
+
+// go test -run TestKeyedTTL -v
+func TestKeyedTTL(t *testing.T) {
+
+	k := NewKeyed(10, time.Minute)
+
+	now := time.Date(2024, 11, 12, 16, 0, 0, 0, time.UTC)
+	k.now = func() time.Time { return now }
+
+	k.Add("a")
+	k.Add("a")
+	k.Add("b")
+
+	now = now.Add(30 * time.Second)
+	if c := k.Add("a"); c != 3 {
+		t.Errorf("TestKeyedTTL a before ttl c:%d != 3", c)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if c := k.Add("a"); c != 1 {
+		t.Errorf("TestKeyedTTL a after ttl c:%d != 1", c)
+	}
+
+	// "b" expired, so is evicted when a new key is added
+	k.Add("c")
+	if l := k.Len(); l != 2 {
+		t.Errorf("TestKeyedTTL Len:%d != 2", l)
+	}
+}
@@ -0,0 +1,199 @@
+package cron
+
+// This .go file holds a minimal 5 field cron expression parser, for scheduled faults
+// "minute hour day-of-month month day-of-week"
+// e.g. "0 14 * * 1-5" is 14:00 Monday to Friday
+//
+// Each field supports "*", a number, a range "1-5", a step "*/10", "0-30/5", or "5/10" ( 5 to the maximum ),
+// and a comma seperated list of these.  Day-of-week is 0-7, where 0 and 7 are Sunday.
+// Like cron, if both day-of-month and day-of-week are restricted, either can match.
+// A field starting with "*", e.g. "*/2", is not restricted.
+// Names ( e.g. "mon", "jan" ) and the "@daily" style macros are not supported
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fields = 5
+)
+
+var (
+	errFields = errors.New("cron expression must have 5 fields: minute hour day-of-month month day-of-week")
+	errRange  = errors.New("value out of range")
+	errStep   = errors.New("invalid step")
+)
+
+// Schedule is a parsed cron expression
+// Each field is a bit set of the allowed values
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar are true if the field starts with "*", for the cron day matching rule
+	domStar bool
+	dowStar bool
+}
+
+type bounds struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	minuteBounds = bounds{"minute", 0, 59}
+	hourBounds   = bounds{"hour", 0, 23}
+	domBounds    = bounds{"day-of-month", 1, 31}
+	monthBounds  = bounds{"month", 1, 12}
+	dowBounds    = bounds{"day-of-week", 0, 7}
+)
+
+// Parse parses the 5 field cron expression
+func Parse(expr string) (s Schedule, err error) {
+
+	f := strings.Fields(expr)
+	if len(f) != fields {
+		return s, errFields
+	}
+
+	if s.minute, err = parseField(f[0], minuteBounds); err != nil {
+		return s, err
+	}
+	if s.hour, err = parseField(f[1], hourBounds); err != nil {
+		return s, err
+	}
+	if s.dom, err = parseField(f[2], domBounds); err != nil {
+		return s, err
+	}
+	if s.month, err = parseField(f[3], monthBounds); err != nil {
+		return s, err
+	}
+	if s.dow, err = parseField(f[4], dowBounds); err != nil {
+		return s, err
+	}
+
+	// 7 is also Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	// a field starting with "*", e.g. "*/2", is unrestricted, as in the standard cron
+	s.domStar = strings.HasPrefix(f[2], "*")
+	s.dowStar = strings.HasPrefix(f[4], "*")
+
+	return s, nil
+}
+
+// Matches returns true if the minute of t matches the schedule
+// The time is matched in the location of t
+func (s Schedule) Matches(t time.Time) bool {
+
+	if s.minute&(1<<uint(t.Minute())) == 0 ||
+		s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Last returns the start of the most recent minute matching the schedule, which is
+// not after t, and not before t minus the lookback.  ok is false if there is no match
+func (s Schedule) Last(t time.Time, lookback time.Duration) (last time.Time, ok bool) {
+
+	earliest := t.Add(-lookback)
+
+	for m := t.Truncate(time.Minute); !m.Before(earliest.Truncate(time.Minute)); m = m.Add(-time.Minute) {
+		if s.Matches(m) {
+			return m, true
+		}
+	}
+
+	return last, false
+}
+
+func parseField(field string, b bounds) (set uint64, err error) {
+
+	for _, part := range strings.Split(field, ",") {
+		bits, err := parsePart(part, b)
+		if err != nil {
+			return 0, fmt.Errorf("%s %q: %w", b.name, field, err)
+		}
+		set |= bits
+	}
+
+	return set, nil
+}
+
+func parsePart(part string, b bounds) (set uint64, err error) {
+
+	step := 1
+	r, s, stepped := strings.Cut(part, "/")
+	if stepped {
+		step, err = strconv.Atoi(s)
+		if err != nil || step < 1 {
+			return 0, errStep
+		}
+		part = r
+	}
+
+	lo, hi := b.min, b.max
+
+	switch {
+	case part == "*":
+	case strings.Contains(part, "-"):
+		l, h, _ := strings.Cut(part, "-")
+		if lo, err = parseValue(l, b); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(h, b); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, errRange
+		}
+	default:
+		if lo, err = parseValue(part, b); err != nil {
+			return 0, err
+		}
+		// "5/10" is 5 to the maximum, in steps of 10, and "5/1" is 5 to the maximum
+		if !stepped {
+			hi = lo
+		}
+	}
+
+	for v := lo; v <= hi; v += step {
+		set |= 1 << uint(v)
+	}
+
+	return set, nil
+}
+
+func parseValue(str string, b bounds) (int, error) {
+
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, err
+	}
+
+	if v < b.min || v > b.max {
+		return 0, errRange
+	}
+
+	return v, nil
+}
@@ -0,0 +1,163 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+type parseTest struct {
+	expr      string
+	expectErr bool
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{expr: "* * * * *", expectErr: false},
+		{expr: "0 14 * * *", expectErr: false},
+		{expr: "*/10 * * * 1-5", expectErr: false},
+		{expr: "0,30 9-17/2 1,15 1-12 0,7", expectErr: false},
+		{expr: "5/15 * * * *", expectErr: false},
+		{expr: "", expectErr: true},
+		{expr: "* * * *", expectErr: true},
+		{expr: "* * * * * *", expectErr: true},
+		{expr: "60 * * * *", expectErr: true},
+		{expr: "* 24 * * *", expectErr: true},
+		{expr: "* * 0 * *", expectErr: true},
+		{expr: "* * * 13 *", expectErr: true},
+		{expr: "* * * * 8", expectErr: true},
+		{expr: "*/0 * * * *", expectErr: true},
+		{expr: "10-5 * * * *", expectErr: true},
+		{expr: "mon * * * *", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expr: %q, expected error: %v, got: %v", tt.expr, tt.expectErr, err)
+			}
+		})
+	}
+}
+
+type matchesTest struct {
+	expr string
+	t    time.Time
+	want bool
+}
+
+// go test -run TestMatches -v
+func TestMatches(t *testing.T) {
+
+	// 2024-01-01 was a Monday
+	monday1400 := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, 1, 7, 14, 0, 0, 0, time.UTC)
+
+	tests := []matchesTest{
+		{expr: "0 14 * * *", t: monday1400, want: true},
+		{expr: "0 14 * * *", t: monday1400.Add(30 * time.Second), want: true},
+		{expr: "0 14 * * *", t: monday1400.Add(time.Minute), want: false},
+		{expr: "*/10 * * * *", t: monday1400.Add(20 * time.Minute), want: true},
+		{expr: "*/10 * * * *", t: monday1400.Add(25 * time.Minute), want: false},
+		{expr: "5/15 * * * *", t: monday1400.Add(35 * time.Minute), want: true},
+		{expr: "0 14 * * 1-5", t: monday1400, want: true},
+		{expr: "0 14 * * 1-5", t: sunday, want: false},
+		{expr: "0 14 * * 7", t: sunday, want: true},
+		{expr: "0 14 * * 0", t: sunday, want: true},
+		{expr: "0 14 1 * *", t: monday1400, want: true},
+		{expr: "0 14 2 * *", t: monday1400, want: false},
+		// day-of-month or day-of-week, when both are restricted
+		{expr: "0 14 2 * 1", t: monday1400, want: true},
+		{expr: "0 14 2 * 0", t: monday1400, want: false},
+		{expr: "0 14 * 2 *", t: monday1400, want: false},
+		// "*/2" is unrestricted, so day-of-month and day-of-week, on Wednesday the 3rd
+		{expr: "0 14 */2 * 1", t: monday1400.Add(48 * time.Hour), want: false},
+		{expr: "0 14 */2 * 1", t: monday1400, want: true},
+		{expr: "0 14 3 * */2", t: monday1400.Add(48 * time.Hour), want: false},
+		// "5/1" is 5 to the maximum
+		{expr: "5/1 * * * *", t: monday1400.Add(6 * time.Minute), want: true},
+		{expr: "5/1 * * * *", t: monday1400.Add(4 * time.Minute), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("expr: %q, error: %v", tt.expr, err)
+			}
+			if got := s.Matches(tt.t); got != tt.want {
+				t.Errorf("expr: %q, t:%s got:%t want:%t", tt.expr, tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+type lastTest struct {
+	name     string
+	expr     string
+	t        time.Time
+	lookback time.Duration
+	want     time.Time
+	ok       bool
+}
+
+// go test -run TestLast -v
+func TestLast(t *testing.T) {
+
+	base := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []lastTest{
+		{
+			name:     "same minute",
+			expr:     "0 14 * * *",
+			t:        base.Add(10 * time.Second),
+			lookback: 5 * time.Minute,
+			want:     base,
+			ok:       true,
+		},
+		{
+			name:     "within lookback",
+			expr:     "0 14 * * *",
+			t:        base.Add(4*time.Minute + 59*time.Second),
+			lookback: 5 * time.Minute,
+			want:     base,
+			ok:       true,
+		},
+		{
+			name:     "after lookback",
+			expr:     "0 14 * * *",
+			t:        base.Add(6 * time.Minute),
+			lookback: 5 * time.Minute,
+			ok:       false,
+		},
+		{
+			name:     "before the schedule",
+			expr:     "0 14 * * *",
+			t:        base.Add(-time.Second),
+			lookback: 5 * time.Minute,
+			ok:       false,
+		},
+		{
+			name:     "most recent",
+			expr:     "*/10 * * * *",
+			t:        base.Add(25 * time.Minute),
+			lookback: time.Hour,
+			want:     base.Add(20 * time.Minute),
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("expr: %q, error: %v", tt.expr, err)
+			}
+			got, ok := s.Last(tt.t, tt.lookback)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("test: %s, got:%s ok:%t want:%s ok:%t", tt.name, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
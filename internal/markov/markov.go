@@ -0,0 +1,112 @@
+package markov
+
+// This .go file holds the two state Markov ( Gilbert-Elliott ) fault model
+//
+// Independent sampling scatters the faults evenly, but real outages are bursts.
+// The model has a good state and a bad state.  Each request first moves between
+// the states, with the transition probabilities, and then faults at the rate of
+// the current state.
+//
+// All the values are parts-per-million ( 0-1000000 ), e.g.
+// "10000,200000,0,900000" is a 1% chance of moving to the bad state, a 20% chance
+// of moving back to the good state ( so bursts average 5 requests ), no faults
+// in the good state, and 90% faults in the bad state
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	fields = 4
+
+	// Good and Bad are the values of the state
+	Good uint64 = 0
+	Bad  uint64 = 1
+)
+
+var (
+	errFields = errors.New("markov must have 4 values: good to bad, bad to good, good rate, bad rate")
+)
+
+// Params are the transition probabilities and the fault rates, in parts-per-million
+type Params struct {
+	GoodToBad int
+	BadToGood int
+	GoodPPM   int
+	BadPPM    int
+}
+
+// Parse parses the comma seperated "good to bad, bad to good, good rate, bad rate"
+func Parse(str string) (p Params, err error) {
+
+	parts := strings.Split(str, ",")
+	if len(parts) != fields {
+		return p, errFields
+	}
+
+	values := make([]int, fields)
+	for i, part := range parts {
+		v, err := strconv.ParseInt(strings.TrimSpace(part), 0, 64)
+		if err != nil {
+			return p, fmt.Errorf("markov value %d: %w", i, err)
+		}
+		values[i], err = validate.ValidateRatePPM(v)
+		if err != nil {
+			return p, fmt.Errorf("markov value %d: %w", i, err)
+		}
+	}
+
+	p = Params{
+		GoodToBad: values[0],
+		BadToGood: values[1],
+		GoodPPM:   values[2],
+		BadPPM:    values[3],
+	}
+
+	return p, nil
+}
+
+// String is the header format of the params
+func (p Params) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", p.GoodToBad, p.BadToGood, p.GoodPPM, p.BadPPM)
+}
+
+// Step moves the state, which is Good or Bad, and returns if the request should fault
+// The state is updated with CompareAndSwap, so concurrent requests sharing a state
+// each make one transition
+func Step(state *atomic.Uint64, p Params) (fault bool, bad bool) {
+
+	for {
+		current := state.Load()
+
+		next := current
+		switch current {
+		case Bad:
+			if rand.SamplePPM(p.BadToGood) {
+				next = Good
+			}
+		default:
+			if rand.SamplePPM(p.GoodToBad) {
+				next = Bad
+			}
+		}
+
+		if state.CompareAndSwap(current, next) {
+			bad = next == Bad
+			break
+		}
+	}
+
+	if bad {
+		return rand.SamplePPM(p.BadPPM), true
+	}
+
+	return rand.SamplePPM(p.GoodPPM), false
+}
@@ -0,0 +1,112 @@
+package markov
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+type parseTest struct {
+	str       string
+	params    Params
+	expectErr bool
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{str: "10000,200000,0,900000", params: Params{10000, 200000, 0, 900000}, expectErr: false},
+		{str: " 1, 2, 3, 4 ", params: Params{1, 2, 3, 4}, expectErr: false},
+		{str: "0,0,0,1000000", params: Params{0, 0, 0, 1000000}, expectErr: false},
+		{str: "", expectErr: true},
+		{str: "1,2,3", expectErr: true},
+		{str: "1,2,3,4,5", expectErr: true},
+		{str: "1,2,3,blah", expectErr: true},
+		{str: "-1,2,3,4", expectErr: true},
+		{str: "1,2,3,1000001", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			p, err := Parse(tt.str)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("str: %q, expected error: %v, got: %v", tt.str, tt.expectErr, err)
+			}
+			if p != tt.params {
+				t.Errorf("str: %q, params:%v != tt.params:%v", tt.str, p, tt.params)
+			}
+		})
+	}
+}
+
+type stepTest struct {
+	name   string
+	params Params
+	start  uint64
+	fault  bool
+	bad    bool
+}
+
+// go test -run TestStep -v
+func TestStep(t *testing.T) {
+	tests := []stepTest{
+		{name: "good stays good, never faults", params: Params{0, 0, 0, 1000000}, start: Good, fault: false, bad: false},
+		{name: "good moves bad, always faults", params: Params{1000000, 0, 0, 1000000}, start: Good, fault: true, bad: true},
+		{name: "bad stays bad", params: Params{0, 0, 0, 1000000}, start: Bad, fault: true, bad: true},
+		{name: "bad moves good", params: Params{0, 1000000, 0, 1000000}, start: Bad, fault: false, bad: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state atomic.Uint64
+			state.Store(tt.start)
+			for i := 0; i < 10; i++ {
+				fault, bad := Step(&state, tt.params)
+				if fault != tt.fault || bad != tt.bad {
+					t.Fatalf("test: %s, i:%d fault:%t bad:%t", tt.name, i, fault, bad)
+				}
+			}
+		})
+	}
+}
+
+// go test -run TestStepBursts -v
+func TestStepBursts(t *testing.T) {
+
+	// bad state 1% of the time on average, with an average burst length of 1/0.2 = 5
+	// the stationary bad probability is p_gb / (p_gb + p_bg) = 0.01 / 0.21
+	p := Params{GoodToBad: 10000, BadToGood: 200000, GoodPPM: 0, BadPPM: 1000000}
+
+	const draws = 1000000
+
+	var (
+		state  atomic.Uint64
+		faults int
+		bursts int
+		prev   bool
+	)
+
+	for i := 0; i < draws; i++ {
+		fault, _ := Step(&state, p)
+		if fault {
+			faults++
+			if !prev {
+				bursts++
+			}
+		}
+		prev = fault
+	}
+
+	expectedRate := 0.01 / 0.21
+	rate := float64(faults) / draws
+	if math.Abs(rate-expectedRate) > 0.005 {
+		t.Errorf("fault rate:%f, expected:%f", rate, expectedRate)
+	}
+
+	meanBurst := float64(faults) / float64(bursts)
+	if meanBurst < 4.5 || meanBurst > 5.5 {
+		t.Errorf("mean burst length:%f, expected 5", meanBurst)
+	}
+
+	t.Logf("fault rate:%f expected:%f mean burst length:%f", rate, expectedRate, meanBurst)
+}
@@ -0,0 +1,527 @@
+package mutate
+
+// This .go file holds the protobuf message mutations, which corrupt a message
+// using protoreflect, so the defensive parsing, and the input validation, of the peer can be tested
+//
+// A mutation is an operation, and optionally the fields it applies to, by name or number
+// e.g. "zero,truncate:message,enum,clear:id|name"
+// e.g. "drop:id,unknown,oversize:name,negative"
+// e.g. "unknown:100|101,strip:7" ( unknown and strip are by field number )
+// Without any fields, the operation applies to all the fields, including the nested messages
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Op is the mutation operation
+type Op string
+
+const (
+	// Zero sets the numbers and bools to zero
+	Zero Op = "zero"
+	// Truncate halves the strings, bytes, and repeated fields
+	Truncate Op = "truncate"
+	// Enum sets the enums to a value which isn't defined
+	Enum Op = "enum"
+	// Clear clears the fields, e.g. the fields which are required by convention
+	Clear Op = "clear"
+	// Drop drops the fields from a request, which is the same as Clear
+	Drop Op = "drop"
+	// Unknown appends an unknown field, with a field number which isn't defined,
+	// or with each of the field numbers, e.g. the fields of a newer schema version
+	Unknown Op = "unknown"
+	// Strip removes the fields, known or unknown, with the field numbers, e.g. the fields
+	// added in a newer schema version, or without field numbers, all the unknown fields
+	Strip Op = "strip"
+	// Oversize grows the strings and bytes to OversizeLength, including the unset fields
+	Oversize Op = "oversize"
+	// Negative sets the signed numbers negative, and the unsigned numbers to the maximum,
+	// including the unset fields
+	Negative Op = "negative"
+)
+
+const (
+	opSeperator     = ":"
+	fieldsSeperator = "|"
+
+	// OversizeLength is the length of an Oversize string or bytes field
+	OversizeLength = 64 * 1024
+
+	// unknownValue is the value of the Unknown fields
+	unknownValue = "faultinjection"
+)
+
+var (
+	errEmpty  = errors.New("mutate must have at least one operation")
+	errField  = errors.New("mutate field is empty")
+	errNumber = errors.New("mutate unknown and strip fields must be valid field numbers")
+)
+
+// Mutation is an operation, on the selected fields
+// Fields are field names or numbers, and empty is all the fields
+type Mutation struct {
+	Op     Op
+	Fields []string
+}
+
+// Parse parses the comma seperated mutations, each "op[:field|field...]"
+// e.g. "zero,truncate:message,enum,clear:id|name"
+func Parse(str string) (ms []Mutation, err error) {
+
+	if strings.TrimSpace(str) == "" {
+		return nil, errEmpty
+	}
+
+	for _, part := range strings.Split(str, ",") {
+
+		op, fields, _ := strings.Cut(strings.TrimSpace(part), opSeperator)
+
+		m := Mutation{Op: Op(strings.ToLower(op))}
+		if !m.Op.valid() {
+			return nil, fmt.Errorf("mutate invalid operation %q", op)
+		}
+
+		if fields != "" {
+			for _, f := range strings.Split(fields, fieldsSeperator) {
+				f = strings.TrimSpace(f)
+				if f == "" {
+					return nil, errField
+				}
+				if (m.Op == Unknown || m.Op == Strip) && !validNumber(f) {
+					return nil, errNumber
+				}
+				m.Fields = append(m.Fields, f)
+			}
+		}
+
+		ms = append(ms, m)
+	}
+
+	return ms, nil
+}
+
+func (op Op) valid() bool {
+	switch op {
+	case Zero, Truncate, Enum, Clear, Drop, Unknown, Strip, Oversize, Negative:
+		return true
+	}
+	return false
+}
+
+// validNumber returns true if the field is a valid field number, outside the reserved range
+func validNumber(f string) bool {
+	n, err := strconv.ParseInt(f, 10, 32)
+	if err != nil {
+		return false
+	}
+	number := protowire.Number(n)
+	return number.IsValid() && (number < protowire.FirstReservedNumber || number > protowire.LastReservedNumber)
+}
+
+// numbers returns the field numbers, which are checked by Parse
+func (mu Mutation) numbers() []protowire.Number {
+	ns := make([]protowire.Number, 0, len(mu.Fields))
+	for _, f := range mu.Fields {
+		n, _ := strconv.ParseInt(f, 10, 32)
+		ns = append(ns, protowire.Number(n))
+	}
+	return ns
+}
+
+// String is the mutations, in the Parse format
+func String(ms []Mutation) string {
+	parts := make([]string, len(ms))
+	for i, m := range ms {
+		parts[i] = string(m.Op)
+		if len(m.Fields) > 0 {
+			parts[i] += opSeperator + strings.Join(m.Fields, fieldsSeperator)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Apply applies the mutations to the message, and returns the number of fields changed
+func Apply(msg proto.Message, ms []Mutation) (changed int) {
+
+	if msg == nil {
+		return 0
+	}
+
+	m := msg.ProtoReflect()
+	if !m.IsValid() {
+		return 0
+	}
+
+	for _, mu := range ms {
+		changed += mu.apply(m)
+	}
+
+	return changed
+}
+
+// apply applies the mutation to the message, and the nested messages
+// Unknown and Strip only apply to the message, and not the nested messages,
+// as the field numbers are per message
+func (mu Mutation) apply(m protoreflect.Message) (changed int) {
+
+	switch mu.Op {
+	case Unknown:
+		return unknown(m, mu.numbers())
+	case Strip:
+		return strip(m, mu.numbers())
+	}
+
+	fds := m.Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+
+		fd := fds.Get(i)
+
+		if mu.selects(fd) && mu.change(m, fd) {
+			changed++
+		}
+
+		// the nested messages, which may have been changed above
+		if fd.Message() == nil || fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			l := m.Get(fd).List()
+			for j := 0; j < l.Len(); j++ {
+				changed += mu.apply(l.Get(j).Message())
+			}
+			continue
+		}
+		changed += mu.apply(m.Mutable(fd).Message())
+	}
+
+	return changed
+}
+
+// selects returns true if the field is selected, by name, JSON name, or number
+func (mu Mutation) selects(fd protoreflect.FieldDescriptor) bool {
+
+	if len(mu.Fields) == 0 {
+		return true
+	}
+
+	number := strconv.Itoa(int(fd.Number()))
+	for _, f := range mu.Fields {
+		if f == string(fd.Name()) || f == fd.JSONName() || f == number {
+			return true
+		}
+	}
+
+	return false
+}
+
+// change applies the operation to the field, and returns true if the field changed
+func (mu Mutation) change(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	switch mu.Op {
+	case Zero:
+		return zero(m, fd)
+	case Truncate:
+		return truncate(m, fd)
+	case Enum:
+		return enum(m, fd)
+	case Clear, Drop:
+		if !m.Has(fd) {
+			return false
+		}
+		m.Clear(fd)
+		return true
+	case Oversize:
+		return oversize(m, fd)
+	case Negative:
+		return negative(m, fd)
+	}
+
+	return false
+}
+
+// zero sets a populated number or bool to zero
+func zero(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	if fd.IsList() || fd.IsMap() || !m.Has(fd) {
+		return false
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind,
+		protoreflect.GroupKind, protoreflect.EnumKind:
+		return false
+	}
+
+	// a field with presence keeps the presence, with the zero value
+	if fd.HasPresence() {
+		m.Set(fd, fd.Default())
+		return true
+	}
+
+	m.Clear(fd)
+
+	return true
+}
+
+// truncate halves a populated string, bytes, or repeated field
+// A string is cut on a rune boundary, so it's still valid UTF-8, which proto3 requires
+func truncate(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	if fd.IsMap() || !m.Has(fd) {
+		return false
+	}
+
+	if fd.IsList() {
+		l := m.Mutable(fd).List()
+		l.Truncate(l.Len() / 2)
+		return true
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s := m.Get(fd).String()
+		n := len(s) / 2
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		m.Set(fd, protoreflect.ValueOfString(s[:n]))
+		return true
+	case protoreflect.BytesKind:
+		b := m.Get(fd).Bytes()
+		m.Set(fd, protoreflect.ValueOfBytes(append([]byte(nil), b[:len(b)/2]...)))
+		return true
+	}
+
+	return false
+}
+
+// enum sets an enum to one more than the largest defined value
+// Unset enums in a oneof are not set, so the oneof isn't changed
+func enum(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	if fd.Kind() != protoreflect.EnumKind || fd.IsMap() {
+		return false
+	}
+
+	if fd.ContainingOneof() != nil && !m.Has(fd) {
+		return false
+	}
+
+	undefined := protoreflect.ValueOfEnum(undefinedEnum(fd.Enum()))
+
+	if fd.IsList() {
+		if !m.Has(fd) {
+			return false
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			l.Set(i, undefined)
+		}
+		return true
+	}
+
+	m.Set(fd, undefined)
+
+	return true
+}
+
+// undefinedEnum returns one more than the largest defined enum value
+func undefinedEnum(ed protoreflect.EnumDescriptor) protoreflect.EnumNumber {
+	var largest protoreflect.EnumNumber
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		if n := values.Get(i).Number(); n > largest {
+			largest = n
+		}
+	}
+	return largest + 1
+}
+
+// unknown appends a length delimited unknown field for each of the numbers,
+// or without numbers, one numbered one more than the largest defined field
+func unknown(m protoreflect.Message, numbers []protowire.Number) int {
+
+	if len(numbers) == 0 {
+		var largest protoreflect.FieldNumber
+		fds := m.Descriptor().Fields()
+		for i := 0; i < fds.Len(); i++ {
+			if n := fds.Get(i).Number(); n > largest {
+				largest = n
+			}
+		}
+
+		number := largest + 1
+		// skip the numbers reserved for the protobuf implementation
+		if number >= protowire.FirstReservedNumber && number <= protowire.LastReservedNumber {
+			number = protowire.LastReservedNumber + 1
+		}
+		numbers = []protowire.Number{number}
+	}
+
+	raw := m.GetUnknown()
+	for _, number := range numbers {
+		raw = protowire.AppendTag(raw, number, protowire.BytesType)
+		raw = protowire.AppendString(raw, unknownValue)
+	}
+	m.SetUnknown(raw)
+
+	return len(numbers)
+}
+
+// strip clears the known fields with the numbers, and removes the unknown fields with the numbers,
+// or without numbers, removes all the unknown fields
+func strip(m protoreflect.Message, numbers []protowire.Number) (changed int) {
+
+	fds := m.Descriptor().Fields()
+	for _, number := range numbers {
+		if fd := fds.ByNumber(number); fd != nil && m.Has(fd) {
+			m.Clear(fd)
+			changed++
+		}
+	}
+
+	raw := m.GetUnknown()
+	if len(raw) == 0 {
+		return changed
+	}
+
+	var kept protoreflect.RawFields
+	for len(raw) > 0 {
+		number, _, n := protowire.ConsumeField(raw)
+		if n < 0 {
+			// malformed unknown fields are all removed
+			changed++
+			break
+		}
+		if len(numbers) > 0 && !slices.Contains(numbers, number) {
+			kept = append(kept, raw[:n]...)
+		} else {
+			changed++
+		}
+		raw = raw[n:]
+	}
+	m.SetUnknown(kept)
+
+	return changed
+}
+
+// oversize grows a string or bytes field to OversizeLength, repeating the value
+func oversize(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	if fd.IsMap() || (fd.ContainingOneof() != nil && !m.Has(fd)) {
+		return false
+	}
+
+	var grow func(v protoreflect.Value) protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		grow = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfString(string(repeat([]byte(v.String()))))
+		}
+	case protoreflect.BytesKind:
+		grow = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfBytes(repeat(v.Bytes()))
+		}
+	default:
+		return false
+	}
+
+	if fd.IsList() {
+		if !m.Has(fd) {
+			return false
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			l.Set(i, grow(l.Get(i)))
+		}
+		return true
+	}
+
+	m.Set(fd, grow(m.Get(fd)))
+
+	return true
+}
+
+// repeat repeats the bytes to OversizeLength, or repeats "x" if there are no bytes
+func repeat(b []byte) []byte {
+	if len(b) == 0 {
+		b = []byte("x")
+	}
+	return bytes.Repeat(b, OversizeLength/len(b)+1)[:OversizeLength]
+}
+
+// negative sets a signed number to a negative value, and an unsigned number to the maximum,
+// which is what a negative number becomes, when it is converted to unsigned
+func negative(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+
+	if fd.IsMap() || (fd.ContainingOneof() != nil && !m.Has(fd)) {
+		return false
+	}
+
+	var neg func(v protoreflect.Value) protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfInt32(int32(negate(v.Int())))
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfInt64(negate(v.Int()))
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfUint32(math.MaxUint32)
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfUint64(math.MaxUint64)
+		}
+	case protoreflect.FloatKind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfFloat32(float32(-math.Max(math.Abs(v.Float()), 1)))
+		}
+	case protoreflect.DoubleKind:
+		neg = func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfFloat64(-math.Max(math.Abs(v.Float()), 1))
+		}
+	default:
+		return false
+	}
+
+	if fd.IsList() {
+		if !m.Has(fd) {
+			return false
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			l.Set(i, neg(l.Get(i)))
+		}
+		return true
+	}
+
+	m.Set(fd, neg(m.Get(fd)))
+
+	return true
+}
+
+// negate returns the negative of a positive number, -1 for zero, and a negative number unchanged
+func negate(i int64) int64 {
+	switch {
+	case i > 0:
+		return -i
+	case i == 0:
+		return -1
+	}
+	return i
+}
@@ -0,0 +1,299 @@
+package mutate
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type parseTest struct {
+	str       string
+	ms        []Mutation
+	expectErr bool
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{str: "zero", ms: []Mutation{{Op: Zero}}, expectErr: false},
+		{str: "zero, TRUNCATE:name", ms: []Mutation{{Op: Zero}, {Op: Truncate, Fields: []string{"name"}}}, expectErr: false},
+		{str: "enum,clear:1|name", ms: []Mutation{{Op: Enum}, {Op: Clear, Fields: []string{"1", "name"}}}, expectErr: false},
+		{str: "drop:id,unknown,oversize:name,negative", ms: []Mutation{{Op: Drop, Fields: []string{"id"}}, {Op: Unknown}, {Op: Oversize, Fields: []string{"name"}}, {Op: Negative}}, expectErr: false},
+		{str: "unknown:100|101,strip", ms: []Mutation{{Op: Unknown, Fields: []string{"100", "101"}}, {Op: Strip}}, expectErr: false},
+		{str: "unknown:name", expectErr: true},
+		{str: "strip:0", expectErr: true},
+		{str: "strip:19000", expectErr: true},
+		{str: "", expectErr: true},
+		{str: "blah", expectErr: true},
+		{str: "zero,", expectErr: true},
+		{str: "clear:name|", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			ms, err := Parse(tt.str)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("str: %q, expected error: %v, got: %v", tt.str, tt.expectErr, err)
+			}
+			if !reflect.DeepEqual(ms, tt.ms) {
+				t.Errorf("str: %q, ms:%v != tt.ms:%v", tt.str, ms, tt.ms)
+			}
+			if err == nil {
+				if _, err := Parse(String(ms)); err != nil {
+					t.Errorf("str: %q, String:%q doesn't parse: %v", tt.str, String(ms), err)
+				}
+			}
+		})
+	}
+}
+
+type applyTest struct {
+	name    string
+	str     string
+	changed int
+	check   func(m *descriptorpb.DescriptorProto) bool
+}
+
+// testMessage is a message with strings, numbers, enums, repeated, and nested messages
+func testMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: proto.String("message"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:   proto.String("id"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+			},
+			{
+				Name:   proto.String("name"),
+				Number: proto.Int32(2),
+			},
+		},
+		ReservedName: []string{"a", "b", "c", "d"},
+	}
+}
+
+// go test -run TestApply -v
+func TestApply(t *testing.T) {
+	tests := []applyTest{
+		{
+			name: "zero", str: "zero", changed: 2,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Field[0].Number != nil && m.Field[0].GetNumber() == 0 && m.Field[1].GetNumber() == 0
+			},
+		},
+		{
+			name: "truncate", str: "truncate", changed: 4,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.GetName() == "mes" && len(m.Field) == 1 && m.Field[0].GetName() == "i" && len(m.ReservedName) == 2
+			},
+		},
+		{
+			name: "truncate field", str: "truncate:name", changed: 3,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.GetName() == "mes" && len(m.Field) == 2 && m.Field[1].GetName() == "na"
+			},
+		},
+		{
+			name: "enum", str: "enum:label", changed: 2,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Field[0].GetLabel() == 4 && m.Field[1].GetLabel() == 4
+			},
+		},
+		{
+			name: "clear by number", str: "clear:2", changed: 1,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return len(m.Field) == 0 && m.GetName() == "message"
+			},
+		},
+		{
+			name: "clear by name in nested", str: "clear:type_name|type", changed: 1,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Field[0].Type == nil && m.Field[0].GetName() == "id"
+			},
+		},
+		{
+			name: "drop", str: "drop:field", changed: 1,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return len(m.Field) == 0
+			},
+		},
+		{
+			name: "unknown", str: "unknown", changed: 1,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				b, err := proto.Marshal(m)
+				if err != nil {
+					return false
+				}
+				var u descriptorpb.DescriptorProto
+				if err := proto.Unmarshal(b, &u); err != nil {
+					return false
+				}
+				return len(u.ProtoReflect().GetUnknown()) > 0 && len(m.Field[0].ProtoReflect().GetUnknown()) == 0
+			},
+		},
+		{
+			name: "unknown by number", str: "unknown:100|101", changed: 2,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return len(m.ProtoReflect().GetUnknown()) > 0
+			},
+		},
+		{
+			name: "strip known by number", str: "strip:1", changed: 1,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Name == nil && m.Field[0].GetName() == "id"
+			},
+		},
+		{
+			name: "unknown then strip", str: "unknown:100|101,strip:100", changed: 3,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				number, _, _ := protowire.ConsumeField(m.ProtoReflect().GetUnknown())
+				return number == 101
+			},
+		},
+		{
+			name: "oversize", str: "oversize:name", changed: 3,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return len(m.GetName()) == OversizeLength && len(m.Field[0].GetName()) == OversizeLength
+			},
+		},
+		{
+			name: "negative", str: "negative:number", changed: 2,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Field[0].GetNumber() == -1 && m.Field[1].GetNumber() == -2
+			},
+		},
+		{
+			name: "no field", str: "clear:blah", changed: 0,
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return proto.Equal(m, testMessage())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := Parse(tt.str)
+			if err != nil {
+				t.Fatalf("Parse(%q) err:%v", tt.str, err)
+			}
+			m := testMessage()
+			if changed := Apply(m, ms); changed != tt.changed {
+				t.Errorf("str:%q, changed:%d != tt.changed:%d, m:%v", tt.str, changed, tt.changed, m)
+			}
+			if !tt.check(m) {
+				t.Errorf("str:%q, unexpected m:%v", tt.str, m)
+			}
+		})
+	}
+}
+
+type schemaTest struct {
+	name  string
+	str   string
+	check func(m *descriptorpb.DescriptorProto) bool
+}
+
+// go test -run TestSchema -v
+// The older schema is emptypb.Empty, so all the fields of the newer schema are unknown
+func TestSchema(t *testing.T) {
+	tests := []schemaTest{
+		{
+			name: "unknown fields are kept",
+			str:  "unknown:100",
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return proto.Equal(m, testMessage())
+			},
+		},
+		{
+			name: "strip newer field",
+			str:  "strip:1",
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return m.Name == nil && len(m.Field) == 2 && len(m.ReservedName) == 4
+			},
+		},
+		{
+			name: "strip all unknown",
+			str:  "strip",
+			check: func(m *descriptorpb.DescriptorProto) bool {
+				return proto.Equal(m, &descriptorpb.DescriptorProto{})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := Parse(tt.str)
+			if err != nil {
+				t.Fatalf("Parse(%q) err:%v", tt.str, err)
+			}
+
+			b, err := proto.Marshal(testMessage())
+			if err != nil {
+				t.Fatal(err)
+			}
+			older := &emptypb.Empty{}
+			if err := proto.Unmarshal(b, older); err != nil {
+				t.Fatal(err)
+			}
+
+			Apply(older, ms)
+
+			b, err = proto.Marshal(older)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := &descriptorpb.DescriptorProto{}
+			if err := proto.Unmarshal(b, m); err != nil {
+				t.Fatal(err)
+			}
+			m.ProtoReflect().SetUnknown(nil)
+
+			if !tt.check(m) {
+				t.Errorf("str:%q, unexpected m:%v", tt.str, m)
+			}
+		})
+	}
+}
+
+type truncateUTF8Test struct {
+	name string
+	want string
+}
+
+// go test -run TestTruncateUTF8 -v
+// The string is cut on a rune boundary, so it's still valid UTF-8
+func TestTruncateUTF8(t *testing.T) {
+	tests := []truncateUTF8Test{
+		{name: "abcd", want: "ab"},
+		{name: "héllo", want: "hé"},
+		{name: "hé", want: "h"},
+		{name: "✓✓", want: "✓"},
+		{name: "✓", want: ""},
+		{name: "日本語", want: "日"},
+	}
+
+	ms, err := Parse("truncate:name")
+	if err != nil {
+		t.Fatalf("Parse err:%v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &descriptorpb.DescriptorProto{Name: proto.String(tt.name)}
+			Apply(m, ms)
+			if m.GetName() != tt.want {
+				t.Errorf("name:%q, got:%q != tt.want:%q", tt.name, m.GetName(), tt.want)
+			}
+			if !utf8.ValidString(m.GetName()) {
+				t.Errorf("name:%q, invalid UTF-8:%q", tt.name, m.GetName())
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package pattern
+
+// This .go file holds the counter based fault patterns, which are
+// shared by the client and server interceptors
+
+// Modulus returns true for every "modulus"th counter, starting at counter "offset"
+// e.g. modulus = 2, offset = 0 is counters 2,4,6... ( the original behaviour )
+// e.g. modulus = 2, offset = 1 is counters 1,3,5...
+// e.g. modulus = 10, offset = 3 is counters 3,13,23...
+func Modulus(counter uint64, modulus uint64, offset uint64) bool {
+	if counter < offset {
+		return false
+	}
+	return (counter-offset)%modulus == 0
+}
+
+// First returns true for "count" counters, starting at counter "offset"
+// counters start at 1, so offset zero (0) is the same as offset one (1)
+// e.g. count = 1, offset = 0 is counter 1, so the first attempt fails, and the retry succeeds
+// e.g. count = 3, offset = 0 is counters 1,2,3
+// e.g. count = 2, offset = 5 is counters 5,6
+func First(counter uint64, count uint64, offset uint64) bool {
+	start := max(offset, 1)
+	return counter >= start && counter < start+count
+}
@@ -0,0 +1,124 @@
+package pattern
+
+import (
+	"reflect"
+	"testing"
+)
+
+type patternTest struct {
+	name   string
+	value  uint64
+	offset uint64
+	loops  uint64
+	faults []uint64
+}
+
+// go test -run TestModulus -v
+func TestModulus(t *testing.T) {
+	tests := []patternTest{
+		{
+			name:   "modulus 1, offset 0",
+			value:  1,
+			offset: 0,
+			loops:  5,
+			faults: []uint64{1, 2, 3, 4, 5},
+		},
+		{
+			name:   "modulus 2, offset 0",
+			value:  2,
+			offset: 0,
+			loops:  10,
+			faults: []uint64{2, 4, 6, 8, 10},
+		},
+		{
+			name:   "modulus 2, offset 1",
+			value:  2,
+			offset: 1,
+			loops:  10,
+			faults: []uint64{1, 3, 5, 7, 9},
+		},
+		{
+			name:   "modulus 10, offset 3",
+			value:  10,
+			offset: 3,
+			loops:  30,
+			faults: []uint64{3, 13, 23},
+		},
+		{
+			name:   "modulus 3, offset 20",
+			value:  3,
+			offset: 20,
+			loops:  30,
+			faults: []uint64{20, 23, 26, 29},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var faults []uint64
+			for counter := uint64(1); counter <= tt.loops; counter++ {
+				if Modulus(counter, tt.value, tt.offset) {
+					faults = append(faults, counter)
+				}
+			}
+			if !reflect.DeepEqual(faults, tt.faults) {
+				t.Errorf("test: %s, faults:%v != tt.faults:%v", tt.name, faults, tt.faults)
+			}
+		})
+	}
+}
+
+// go test -run TestFirst -v
+func TestFirst(t *testing.T) {
+	tests := []patternTest{
+		{
+			name:   "first 1, offset 0",
+			value:  1,
+			offset: 0,
+			loops:  5,
+			faults: []uint64{1},
+		},
+		{
+			name:   "first 1, offset 1",
+			value:  1,
+			offset: 1,
+			loops:  5,
+			faults: []uint64{1},
+		},
+		{
+			name:   "first 3, offset 0",
+			value:  3,
+			offset: 0,
+			loops:  10,
+			faults: []uint64{1, 2, 3},
+		},
+		{
+			name:   "first 2, offset 5",
+			value:  2,
+			offset: 5,
+			loops:  10,
+			faults: []uint64{5, 6},
+		},
+		{
+			name:   "first 10, offset 8, loops 10",
+			value:  10,
+			offset: 8,
+			loops:  10,
+			faults: []uint64{8, 9, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var faults []uint64
+			for counter := uint64(1); counter <= tt.loops; counter++ {
+				if First(counter, tt.value, tt.offset) {
+					faults = append(faults, counter)
+				}
+			}
+			if !reflect.DeepEqual(faults, tt.faults) {
+				t.Errorf("test: %s, faults:%v != tt.faults:%v", tt.name, faults, tt.faults)
+			}
+		})
+	}
+}
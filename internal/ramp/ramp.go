@@ -0,0 +1,165 @@
+package ramp
+
+// This .go file holds the fault rate ramp profiles, to find the fault rate
+// at which the clients tip over
+//
+// The rate moves from "from" percent to "to" percent over the duration, either
+// linearly, or in steps.  At the end the rate holds at "to", or falls back to
+// "from" over the same duration, and then holds at "from".
+//
+// The format is "from,to,duration[,shape[,end]]"
+// e.g. "0,50,5m" is 0% rising linearly to 50% over 5 minutes, and then holding at 50%
+// e.g. "10,100,10m,step10,fall" is 10% to 100% in 10 steps of 1 minute, and then back down
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	minFields = 3
+	maxFields = 5
+
+	ppmPerPercent = 10000
+
+	linearShape = "linear"
+	stepShape   = "step"
+	holdEnd     = "hold"
+	fallEnd     = "fall"
+
+	maxDuration = 24 * time.Hour
+	maxSteps    = 1000
+)
+
+var (
+	errFields   = errors.New("ramp must be from,to,duration[,shape[,end]]")
+	errDuration = errors.New("ramp duration must be greater than zero, and 24h or less")
+	errShape    = errors.New("ramp shape must be linear or stepN, e.g. step5, with 2-1000 steps")
+	errEnd      = errors.New("ramp end must be hold or fall")
+)
+
+// Profile is a parsed ramp
+// Steps zero (0) is linear, otherwise the number of levels from From to To inclusive
+type Profile struct {
+	FromPPM  int
+	ToPPM    int
+	Duration time.Duration
+	Steps    int
+	Fall     bool
+}
+
+// Parse parses the "from,to,duration[,shape[,end]]" ramp
+// from and to are percent 0-100, duration is a Go duration e.g. "5m"
+// shape is "linear" ( the default ) or "stepN", and end is "hold" ( the default ) or "fall"
+func Parse(str string) (p Profile, err error) {
+
+	parts := strings.Split(str, ",")
+	if len(parts) < minFields || len(parts) > maxFields {
+		return p, errFields
+	}
+
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	if p.FromPPM, err = parsePercent(parts[0]); err != nil {
+		return p, fmt.Errorf("ramp from: %w", err)
+	}
+
+	if p.ToPPM, err = parsePercent(parts[1]); err != nil {
+		return p, fmt.Errorf("ramp to: %w", err)
+	}
+
+	p.Duration, err = time.ParseDuration(parts[2])
+	if err != nil {
+		return p, fmt.Errorf("ramp duration: %w", err)
+	}
+	if p.Duration <= 0 || p.Duration > maxDuration {
+		return p, errDuration
+	}
+
+	if len(parts) > 3 {
+		if p.Steps, err = parseShape(parts[3]); err != nil {
+			return p, err
+		}
+	}
+
+	if len(parts) > 4 {
+		switch strings.ToLower(parts[4]) {
+		case holdEnd:
+		case fallEnd:
+			p.Fall = true
+		default:
+			return p, errEnd
+		}
+	}
+
+	return p, nil
+}
+
+func parsePercent(str string) (int, error) {
+
+	v, err := strconv.ParseInt(str, 0, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return validate.ValidateRatePPM(v * ppmPerPercent)
+}
+
+func parseShape(str string) (steps int, err error) {
+
+	shape := strings.ToLower(str)
+
+	if shape == linearShape {
+		return 0, nil
+	}
+
+	if !strings.HasPrefix(shape, stepShape) {
+		return 0, errShape
+	}
+
+	steps, err = strconv.Atoi(strings.TrimPrefix(shape, stepShape))
+	if err != nil || steps < 2 || steps > maxSteps {
+		return 0, errShape
+	}
+
+	return steps, nil
+}
+
+// PPM returns the fault rate in parts-per-million, after the elapsed time
+func (p Profile) PPM(elapsed time.Duration) int {
+
+	switch {
+	case elapsed <= 0:
+		return p.FromPPM
+	case elapsed < p.Duration:
+		return p.between(elapsed, p.FromPPM, p.ToPPM)
+	case !p.Fall:
+		return p.ToPPM
+	case elapsed < 2*p.Duration:
+		return p.between(elapsed-p.Duration, p.ToPPM, p.FromPPM)
+	default:
+		return p.FromPPM
+	}
+}
+
+// between moves the rate from a to b, linearly or in steps
+func (p Profile) between(elapsed time.Duration, a int, b int) int {
+
+	fraction := float64(elapsed) / float64(p.Duration)
+
+	if p.Steps > 0 {
+		// the duration is split into Steps intervals, the first is a, and the last is b
+		level := math.Floor(fraction * float64(p.Steps))
+		fraction = level / float64(p.Steps-1)
+	}
+
+	return a + int(math.Round(float64(b-a)*fraction))
+}
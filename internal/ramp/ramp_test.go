@@ -0,0 +1,84 @@
+package ramp
+
+import (
+	"testing"
+	"time"
+)
+
+type parseTest struct {
+	str       string
+	profile   Profile
+	expectErr bool
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{str: "0,50,5m", profile: Profile{FromPPM: 0, ToPPM: 500000, Duration: 5 * time.Minute}},
+		{str: "10, 100, 10m, step10, fall", profile: Profile{FromPPM: 100000, ToPPM: 1000000, Duration: 10 * time.Minute, Steps: 10, Fall: true}},
+		{str: "50,0,1s,linear,hold", profile: Profile{FromPPM: 500000, ToPPM: 0, Duration: time.Second}},
+		{str: "", expectErr: true},
+		{str: "0,50", expectErr: true},
+		{str: "0,50,5m,linear,hold,extra", expectErr: true},
+		{str: "-1,50,5m", expectErr: true},
+		{str: "0,101,5m", expectErr: true},
+		{str: "0,50,blah", expectErr: true},
+		{str: "0,50,0s", expectErr: true},
+		{str: "0,50,25h", expectErr: true},
+		{str: "0,50,5m,curve", expectErr: true},
+		{str: "0,50,5m,step1", expectErr: true},
+		{str: "0,50,5m,step1001", expectErr: true},
+		{str: "0,50,5m,linear,stop", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			p, err := Parse(tt.str)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("str: %q, expected error: %v, got: %v", tt.str, tt.expectErr, err)
+			}
+			if !tt.expectErr && p != tt.profile {
+				t.Errorf("str: %q, profile:%+v != tt.profile:%+v", tt.str, p, tt.profile)
+			}
+		})
+	}
+}
+
+type ppmTest struct {
+	name    string
+	ramp    string
+	elapsed time.Duration
+	ppm     int
+}
+
+// go test -run TestPPM -v
+func TestPPM(t *testing.T) {
+	tests := []ppmTest{
+		{name: "linear start", ramp: "0,50,10m", elapsed: 0, ppm: 0},
+		{name: "linear half", ramp: "0,50,10m", elapsed: 5 * time.Minute, ppm: 250000},
+		{name: "linear end", ramp: "0,50,10m", elapsed: 10 * time.Minute, ppm: 500000},
+		{name: "linear hold", ramp: "0,50,10m", elapsed: time.Hour, ppm: 500000},
+		{name: "linear down", ramp: "50,10,10m", elapsed: 5 * time.Minute, ppm: 300000},
+		{name: "linear fall half", ramp: "0,50,10m,linear,fall", elapsed: 15 * time.Minute, ppm: 250000},
+		{name: "linear fall end", ramp: "0,50,10m,linear,fall", elapsed: 20 * time.Minute, ppm: 0},
+		{name: "step first", ramp: "0,100,10m,step5", elapsed: time.Minute, ppm: 0},
+		{name: "step second", ramp: "0,100,10m,step5", elapsed: 2 * time.Minute, ppm: 250000},
+		{name: "step third", ramp: "0,100,10m,step5", elapsed: 5 * time.Minute, ppm: 500000},
+		{name: "step last", ramp: "0,100,10m,step5", elapsed: 9 * time.Minute, ppm: 1000000},
+		{name: "step hold", ramp: "0,100,10m,step5", elapsed: 11 * time.Minute, ppm: 1000000},
+		{name: "step fall", ramp: "0,100,10m,step5,fall", elapsed: 12 * time.Minute, ppm: 750000},
+		{name: "negative elapsed", ramp: "20,100,10m", elapsed: -time.Minute, ppm: 200000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.ramp)
+			if err != nil {
+				t.Fatalf("test: %s, Parse error:%v", tt.name, err)
+			}
+			if ppm := p.PPM(tt.elapsed); ppm != tt.ppm {
+				t.Errorf("test: %s, ppm:%d != tt.ppm:%d", tt.name, ppm, tt.ppm)
+			}
+		})
+	}
+}
@@ -28,6 +28,23 @@ func FastRandNInt() int {
 	return int(FastRandN(100))
 }
 
+// FastRandNPPM returns 0-999999, for parts-per-million sampling
+func FastRandNPPM() int {
+	return int(FastRandN(1000000))
+}
+
+// SamplePercent returns true with probability of exactly percent/100
+// FastRandNInt is uniform over 0-99, so exactly "percent" of the 100 values are < percent
+// percent <= 0 never returns true, and percent >= 100 always returns true
+func SamplePercent(percent int) bool {
+	return FastRandNInt() < percent
+}
+
+// SamplePPM returns true with probability of exactly ppm/1000000
+func SamplePPM(ppm int) bool {
+	return FastRandNPPM() < ppm
+}
+
 // randomFaultCode returns ANY random fault code ( 1-16 )
 // does NOT return code 0
 func RandomFaultCode() (code codes.Code) {
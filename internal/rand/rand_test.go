@@ -1,6 +1,8 @@
 package rand
 
 import (
+	"math"
+	"strconv"
 	"testing"
 
 	"google.golang.org/grpc/codes"
@@ -117,3 +119,124 @@ func ConvertSliceToMap[T any, K comparable, V any](slice []T, keyMapper func(T)
 	}
 	return result
 }
+
+// chiSquareCritical99 is the chi-square critical value for 99 degrees of freedom
+// at p = 0.000001, so a correct FastRandNInt fails this test ~1 in a million runs
+// ( Wilson-Hilferty approximation )
+const chiSquareCritical99 = 181.0
+
+// go test -run TestFastRandNIntChiSquare -v
+func TestFastRandNIntChiSquare(t *testing.T) {
+
+	draws := 1000000
+
+	var buckets [100]int
+	for i := 0; i < draws; i++ {
+		r := FastRandNInt()
+		if r < 0 || r > 99 {
+			t.Fatalf("TestFastRandNIntChiSquare r:%d out of range 0-99", r)
+		}
+		buckets[r]++
+	}
+
+	expected := float64(draws) / float64(len(buckets))
+
+	var chiSquare float64
+	for _, observed := range buckets {
+		d := float64(observed) - expected
+		chiSquare += d * d / expected
+	}
+
+	if chiSquare > chiSquareCritical99 {
+		t.Errorf("TestFastRandNIntChiSquare chiSquare:%.3f > critical:%.3f", chiSquare, chiSquareCritical99)
+	}
+}
+
+// binomialBounds returns the min and max acceptable count of true samples
+// for draws at probability p, allowing sigmas standard deviations either side
+func binomialBounds(draws int, p float64, sigmas float64) (minHits int, maxHits int) {
+	mean := float64(draws) * p
+	slop := sigmas*math.Sqrt(float64(draws)*p*(1-p)) + 1
+	return int(math.Floor(mean - slop)), int(math.Ceil(mean + slop))
+}
+
+// TestSamplePercent checks every supported percent value produces the
+// configured probability, within 6 standard deviations of the binomial mean.
+// With the previous "FastRandNInt() > value" check, the real probability was
+// (value+1)/100, which is ~30 standard deviations away for value 1
+// go test -run TestSamplePercent -v
+func TestSamplePercent(t *testing.T) {
+
+	draws := 100000
+
+	for percent := 0; percent <= 100; percent++ {
+		t.Run(strconv.Itoa(percent), func(t *testing.T) {
+
+			var hits int
+			for i := 0; i < draws; i++ {
+				if SamplePercent(percent) {
+					hits++
+				}
+			}
+
+			p := float64(percent) / 100
+			minHits, maxHits := binomialBounds(draws, p, 6)
+
+			switch percent {
+			case 0:
+				minHits, maxHits = 0, 0
+			case 100:
+				minHits, maxHits = draws, draws
+			}
+
+			if hits < minHits || hits > maxHits {
+				t.Errorf("TestSamplePercent percent:%d hits:%d not in [%d,%d]", percent, hits, minHits, maxHits)
+			}
+		})
+	}
+}
+
+type samplePPMTest struct {
+	ppm   int
+	draws int
+}
+
+// go test -run TestSamplePPM -v
+func TestSamplePPM(t *testing.T) {
+	tests := []samplePPMTest{
+		{ppm: 0, draws: 1000000},
+		{ppm: 1, draws: 1000000},
+		{ppm: 10, draws: 1000000},
+		{ppm: 100, draws: 1000000},
+		{ppm: 10000, draws: 100000},
+		{ppm: 500000, draws: 100000},
+		{ppm: 999999, draws: 100000},
+		{ppm: 1000000, draws: 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.ppm), func(t *testing.T) {
+
+			var hits int
+			for i := 0; i < tt.draws; i++ {
+				if SamplePPM(tt.ppm) {
+					hits++
+				}
+			}
+
+			p := float64(tt.ppm) / 1000000
+			minHits, maxHits := binomialBounds(tt.draws, p, 6)
+
+			switch tt.ppm {
+			case 0:
+				minHits, maxHits = 0, 0
+			case 1000000:
+				minHits, maxHits = tt.draws, tt.draws
+			}
+
+			if hits < minHits || hits > maxHits {
+				t.Errorf("TestSamplePPM ppm:%d hits:%d not in [%d,%d]", tt.ppm, hits, minHits, maxHits)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package sequence
+
+// This .go file holds the scripted fault sequences
+// e.g. "ok,14,14,ok,4,ok" means the first request succeeds, the second and third
+// requests fault with code 14 (unavailable), the fourth succeeds, the fifth
+// faults with code 4 (deadline exceeded), and the sixth succeeds
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	okStep = "ok"
+
+	// maxSteps limits the size of the script, which is sent in a header
+	maxSteps = 1000
+)
+
+var (
+	errEmptySequence   = errors.New("empty sequence")
+	errSequenceTooLong = errors.New("sequence too long")
+)
+
+// Step is a single step of a sequence
+// Fault false is "ok", so the request is not faulted
+type Step struct {
+	Fault bool
+	Code  codes.Code
+}
+
+// Parse converts a comma seperated script into steps
+// each step is either "ok" or a valid GRPC status code 0-16
+func Parse(script string) (steps []Step, err error) {
+
+	if strings.TrimSpace(script) == "" {
+		return steps, errEmptySequence
+	}
+
+	parts := strings.Split(script, ",")
+	if len(parts) > maxSteps {
+		return steps, errSequenceTooLong
+	}
+
+	for i := 0; i < len(parts); i++ {
+		part := strings.ToLower(strings.TrimSpace(parts[i]))
+
+		if part == okStep {
+			steps = append(steps, Step{})
+			continue
+		}
+
+		c, errP := strconv.ParseInt(part, 0, 64)
+		if errP != nil {
+			return steps, errP
+		}
+		code, errV := validate.ValidateCode(c)
+		if errV != nil {
+			return steps, errV
+		}
+		steps = append(steps, Step{Fault: true, Code: codes.Code(code)})
+	}
+
+	return steps, nil
+}
+
+// At returns the step for the request at position (starting at 1)
+// once the sequence is finished, if repeat is true the sequence starts again,
+// otherwise all the following requests are "ok"
+func At(steps []Step, position uint64, repeat bool) Step {
+
+	if len(steps) == 0 || position == 0 {
+		return Step{}
+	}
+
+	i := position - 1
+	if i >= uint64(len(steps)) {
+		if !repeat {
+			return Step{}
+		}
+		i = i % uint64(len(steps))
+	}
+
+	return steps[i]
+}
@@ -0,0 +1,140 @@
+package sequence
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+type parseTest struct {
+	name      string
+	script    string
+	expectErr bool
+	steps     []Step
+}
+
+// go test -run TestParse -v
+func TestParse(t *testing.T) {
+	tests := []parseTest{
+		{
+			name:      "valid ok",
+			script:    "ok",
+			expectErr: false,
+			steps:     []Step{{}},
+		},
+		{
+			name:      "valid ok,14,14,ok,4,ok",
+			script:    "ok,14,14,ok,4,ok",
+			expectErr: false,
+			steps: []Step{
+				{},
+				{Fault: true, Code: codes.Unavailable},
+				{Fault: true, Code: codes.Unavailable},
+				{},
+				{Fault: true, Code: codes.DeadlineExceeded},
+				{},
+			},
+		},
+		{
+			name:      "valid spaces and upper case",
+			script:    " OK, 14 ",
+			expectErr: false,
+			steps: []Step{
+				{},
+				{Fault: true, Code: codes.Unavailable},
+			},
+		},
+		{
+			name:      "invalid empty",
+			script:    "",
+			expectErr: true,
+		},
+		{
+			name:      "invalid code 17",
+			script:    "ok,17",
+			expectErr: true,
+		},
+		{
+			name:      "invalid blah",
+			script:    "ok,blah",
+			expectErr: true,
+		},
+		{
+			name:      "invalid ,,",
+			script:    ",,",
+			expectErr: true,
+		},
+		{
+			name:      "invalid too long",
+			script:    strings.Repeat("ok,", maxSteps) + "ok",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := Parse(tt.script)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if !tt.expectErr && !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("test: %s, steps:%v != tt.steps:%v", tt.name, steps, tt.steps)
+			}
+		})
+	}
+}
+
+type atTest struct {
+	name   string
+	script string
+	repeat bool
+	loops  uint64
+	faults []uint64
+}
+
+// go test -run TestAt -v
+func TestAt(t *testing.T) {
+	tests := []atTest{
+		{
+			name:   "ok,14,14,ok,4,ok no repeat",
+			script: "ok,14,14,ok,4,ok",
+			repeat: false,
+			loops:  12,
+			faults: []uint64{2, 3, 5},
+		},
+		{
+			name:   "ok,14,14,ok,4,ok repeat",
+			script: "ok,14,14,ok,4,ok",
+			repeat: true,
+			loops:  12,
+			faults: []uint64{2, 3, 5, 8, 9, 11},
+		},
+		{
+			name:   "14,ok repeat",
+			script: "14,ok",
+			repeat: true,
+			loops:  6,
+			faults: []uint64{1, 3, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := Parse(tt.script)
+			if err != nil {
+				t.Fatalf("test: %s, Parse error: %v", tt.name, err)
+			}
+			var faults []uint64
+			for position := uint64(1); position <= tt.loops; position++ {
+				if At(steps, position, tt.repeat).Fault {
+					faults = append(faults, position)
+				}
+			}
+			if !reflect.DeepEqual(faults, tt.faults) {
+				t.Errorf("test: %s, faults:%v != tt.faults:%v", tt.name, faults, tt.faults)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package signature
+
+// This .go file holds the HMAC signature of the fault headers, so the server
+// only obeys fault headers from callers who know the shared secret
+//
+// The signature is HMAC-SHA256 over the fault headers, sorted by key, including the
+// "faultexpiry" unix time, and the method the headers are for.  The keys and values are
+// length prefixed, so different header values can't produce the same input.
+// The signature is sent, hex encoded, in the "faultsignature" header
+//
+// The method is the "faulttarget", if there is one, so the signed headers are valid at
+// each hop to the target, otherwise it's the full gRPC method which was called, so a
+// captured signature can't be replayed against another method
+//
+// "faulthops" is not signed, because it is decremented at each service hop,
+// so the signed headers can be propagated downstream unchanged.  Instead the initial
+// hops are signed in "faulthopbudget", and "faulthops" can't be more than the budget
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	SignatureHeader = "faultsignature"
+	ExpiryHeader    = "faultexpiry"
+	HopsHeader      = "faulthops"
+	HopBudgetHeader = "faulthopbudget"
+	TargetHeader    = "faulttarget"
+
+	// DefaultMaxAge is the longest time a signature is valid, when MaxAge is zero (0)
+	DefaultMaxAge = 5 * time.Minute
+
+	// DefaultTTL is the time until the signature expires, when the ttl is zero (0)
+	DefaultTTL = time.Minute
+)
+
+var (
+	errMissing   = errors.New("missing faultsignature or faultexpiry")
+	errExpiry    = errors.New("invalid faultexpiry")
+	errExpired   = errors.New("faultexpiry has passed")
+	errTooLong   = errors.New("faultexpiry is too far in the future")
+	errSignature = errors.New("invalid faultsignature")
+	errHops      = errors.New("faulthops is more than the signed faulthopbudget")
+)
+
+// faultHeaders are all the fault headers, which are signed, stripped, and propagated
+// Other headers, e.g. "faulttolerance-mode", are application headers
+var faultHeaders = map[string]bool{
+	"faultcodes":      true,
+	"faultpercent":    true,
+	"faultppm":        true,
+	"faultmodulus":    true,
+	"faultoffset":     true,
+	"faultfirst":      true,
+	"faultsequence":   true,
+	"faultrepeat":     true,
+	"faultsession":    true,
+	"faultscope":      true,
+	"faultdelay":      true,
+	"faultmarkov":     true,
+	"faultramp":       true,
+	"faultconnection": true,
+	"faultcorrupt":    true,
+	"faultpanic":      true,
+	"faultcancel":     true,
+	TargetHeader:      true,
+	HopsHeader:        true,
+	HopBudgetHeader:   true,
+	SignatureHeader:   true,
+	ExpiryHeader:      true,
+}
+
+// IsFaultHeader returns true if the metadata key is a fault header
+func IsFaultHeader(key string) bool {
+	return faultHeaders[key]
+}
+
+// HasFaultHeaders returns true if there are any fault headers
+func HasFaultHeaders(md metadata.MD) bool {
+	for k := range md {
+		if IsFaultHeader(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign adds the "faultexpiry" and "faultsignature" headers to the metadata, and the
+// "faulthopbudget", if there is a "faulthops"
+// method is the full gRPC method being called, which is signed if there is no "faulttarget"
+// The signature expires after the ttl, and zero (0) uses DefaultTTL
+func Sign(md metadata.MD, method string, secret []byte, ttl time.Duration, now time.Time) {
+
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	if hops := md.Get(HopsHeader); len(hops) > 0 {
+		md.Set(HopBudgetHeader, hops[0])
+	}
+
+	md.Set(ExpiryHeader, strconv.FormatInt(now.Add(ttl).Unix(), 10))
+	md.Set(SignatureHeader, hex.EncodeToString(sum(md, method, secret)))
+}
+
+// Verify checks the "faultsignature" and "faultexpiry" headers
+// method is the full gRPC method being called
+// The expiry must be in the future, and no more than maxAge in the future,
+// so a captured signature can't be replayed for long
+// The "faulthops" must not be more than the signed "faulthopbudget"
+func Verify(md metadata.MD, method string, secret []byte, maxAge time.Duration, now time.Time) error {
+
+	sig := md.Get(SignatureHeader)
+	exp := md.Get(ExpiryHeader)
+	if len(sig) == 0 || len(exp) == 0 {
+		return errMissing
+	}
+
+	expiry, err := strconv.ParseInt(exp[0], 10, 64)
+	if err != nil {
+		return errExpiry
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	e := time.Unix(expiry, 0)
+	if !now.Before(e) {
+		return errExpired
+	}
+	if e.After(now.Add(maxAge)) {
+		return errTooLong
+	}
+
+	got, err := hex.DecodeString(sig[0])
+	if err != nil {
+		return errSignature
+	}
+
+	if !hmac.Equal(got, sum(md, method, secret)) {
+		return errSignature
+	}
+
+	if hops := md.Get(HopsHeader); len(hops) > 0 {
+		budget := md.Get(HopBudgetHeader)
+		if len(budget) == 0 {
+			return errHops
+		}
+		h, errH := strconv.Atoi(hops[0])
+		b, errB := strconv.Atoi(budget[0])
+		if errH != nil || errB != nil || h > b {
+			return errHops
+		}
+	}
+
+	return nil
+}
+
+// sum is the HMAC of the bound method, and the sorted fault headers, except the signature and hops
+// Each string is length prefixed, and each header has the number of values
+func sum(md metadata.MD, method string, secret []byte) []byte {
+
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		if IsFaultHeader(k) && k != SignatureHeader && k != HopsHeader {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha256.New, secret)
+
+	if target := md.Get(TargetHeader); len(target) > 0 {
+		method = target[0]
+	}
+	writeString(mac, method)
+
+	for _, k := range keys {
+		writeString(mac, k)
+		writeLength(mac, len(md[k]))
+		for _, v := range md[k] {
+			writeString(mac, v)
+		}
+	}
+
+	return mac.Sum(nil)
+}
+
+// writeLength writes the length as 8 bytes, big endian
+func writeLength(w io.Writer, n int) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(n))
+	w.Write(b[:])
+}
+
+// writeString writes the length prefixed string
+func writeString(w io.Writer, s string) {
+	writeLength(w, len(s))
+	io.WriteString(w, s)
+}
@@ -0,0 +1,223 @@
+package signature
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	echoMethod = "/grpc.examples.echo.Echo/UnaryEcho"
+)
+
+type verifyTest struct {
+	name      string
+	md        func() metadata.MD
+	method    string
+	secret    string
+	maxAge    time.Duration
+	now       time.Duration
+	expectErr bool
+}
+
+// go test -run TestVerify -v
+func TestVerify(t *testing.T) {
+
+	secret := []byte("test secret")
+	now := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+
+	signed := func() metadata.MD {
+		md := metadata.Pairs("faultpercent", "50", "faultcodes", "14", "x-other", "not signed")
+		Sign(md, echoMethod, secret, time.Minute, now)
+		return md
+	}
+
+	signedHops := func() metadata.MD {
+		md := metadata.Pairs("faultcodes", "14", TargetHeader, "C", HopsHeader, "2")
+		Sign(md, echoMethod, secret, time.Minute, now)
+		return md
+	}
+
+	tests := []verifyTest{
+		{
+			name:   "valid",
+			md:     signed,
+			secret: "test secret",
+		},
+		{
+			name: "valid, other header changed",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set("x-other", "changed")
+				return md
+			},
+			secret: "test secret",
+		},
+		{
+			name: "valid, application header with the fault prefix changed",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set("faulttolerance-mode", "changed")
+				return md
+			},
+			secret: "test secret",
+		},
+		{
+			name:      "another method",
+			md:        signed,
+			method:    "/grpc.examples.echo.Echo/ServerStreamingEcho",
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name:   "valid, hops, another method, bound to the target",
+			md:     signedHops,
+			method: "/grpc.examples.echo.Echo/ServerStreamingEcho",
+			secret: "test secret",
+		},
+		{
+			name: "valid, hops decremented",
+			md: func() metadata.MD {
+				md := signedHops()
+				md.Set(HopsHeader, "1")
+				return md
+			},
+			secret: "test secret",
+		},
+		{
+			name: "hops more than the budget",
+			md: func() metadata.MD {
+				md := signedHops()
+				md.Set(HopsHeader, "3")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "hops added, no budget",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set(HopsHeader, "1")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "hop budget changed",
+			md: func() metadata.MD {
+				md := signedHops()
+				md.Set(HopBudgetHeader, "5")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "target changed",
+			md: func() metadata.MD {
+				md := signedHops()
+				md.Set(TargetHeader, "B")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "values joined differently",
+			md: func() metadata.MD {
+				md := metadata.Pairs("faultcodes", "14,10")
+				Sign(md, echoMethod, secret, time.Minute, now)
+				md["faultcodes"] = []string{"14", "10"}
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name:      "wrong secret",
+			md:        signed,
+			secret:    "wrong secret",
+			expectErr: true,
+		},
+		{
+			name: "fault header changed",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set("faultpercent", "100")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "fault header added",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set("faultdelay", "1m")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name:      "expired",
+			md:        signed,
+			secret:    "test secret",
+			now:       time.Minute,
+			expectErr: true,
+		},
+		{
+			name:      "expiry beyond max age",
+			md:        signed,
+			secret:    "test secret",
+			maxAge:    30 * time.Second,
+			expectErr: true,
+		},
+		{
+			name:      "unsigned",
+			md:        func() metadata.MD { return metadata.Pairs("faultpercent", "50") },
+			secret:    "test secret",
+			expectErr: true,
+		},
+		{
+			name: "invalid hex",
+			md: func() metadata.MD {
+				md := signed()
+				md.Set(SignatureHeader, "zz")
+				return md
+			},
+			secret:    "test secret",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := echoMethod
+			if tt.method != "" {
+				method = tt.method
+			}
+			err := Verify(tt.md(), method, []byte(tt.secret), tt.maxAge, now.Add(tt.now))
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+		})
+	}
+}
+
+// go test -run TestHasFaultHeaders -v
+func TestHasFaultHeaders(t *testing.T) {
+
+	if HasFaultHeaders(metadata.Pairs("x-other", "1")) {
+		t.Error("x-other is not a fault header")
+	}
+	if !HasFaultHeaders(metadata.Pairs("x-other", "1", "faultmodulus", "2")) {
+		t.Error("faultmodulus is a fault header")
+	}
+	if HasFaultHeaders(metadata.Pairs("faulttolerance-mode", "1")) {
+		t.Error("faulttolerance-mode is not a fault header")
+	}
+}
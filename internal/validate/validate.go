@@ -1,11 +1,22 @@
 package validate
 
-import "errors"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 var (
 	errInvalidModulus = errors.New("invalid modulus")
 	errInvalidPercent = errors.New("invalid percent")
+	errInvalidPPM     = errors.New("invalid ppm")
+	errInvalidRatePPM = errors.New("invalid rate ppm")
+	errInvalidOffset  = errors.New("invalid offset")
+	errInvalidFirst   = errors.New("invalid first")
+	errInvalidScope   = errors.New("invalid scope")
+	errInvalidDelay   = errors.New("invalid delay")
 	errInvalidCode    = errors.New("invalid code")
+	errInvalidHops    = errors.New("invalid hops")
 )
 
 // ValidateModulus ensure the modulus is between 1-10000 inclusive
@@ -24,6 +35,40 @@ func ValidatePercent(percent int64) (percentInt int, err error) {
 	return int(percent), nil
 }
 
+// ValidatePPM ensure the parts-per-million is between 1-1000000 inclusive
+// e.g. 1 = 0.0001%, 100 = 0.01%, 10000 = 1%, 1000000 = 100%
+func ValidatePPM(ppm int64) (ppmInt int, err error) {
+	if ppm < 1 || ppm > 1000000 {
+		return ppmInt, errInvalidPPM
+	}
+	return int(ppm), nil
+}
+
+// ValidateRatePPM ensure the parts-per-million rate is between 0-1000000 inclusive
+// unlike ValidatePPM, zero is allowed, e.g. a probability of never
+func ValidateRatePPM(ppm int64) (ppmInt int, err error) {
+	if ppm < 0 || ppm > 1000000 {
+		return ppmInt, errInvalidRatePPM
+	}
+	return int(ppm), nil
+}
+
+// ValidateOffset ensure the offset is between 0-1000000 inclusive
+func ValidateOffset(offset int64) (offsetInt uint64, err error) {
+	if offset < 0 || offset > 1000000 {
+		return offsetInt, errInvalidOffset
+	}
+	return uint64(offset), nil
+}
+
+// ValidateFirst ensure the first count is between 1-1000000 inclusive
+func ValidateFirst(first int64) (firstInt uint64, err error) {
+	if first < 1 || first > 1000000 {
+		return firstInt, errInvalidFirst
+	}
+	return uint64(first), nil
+}
+
 // ValidatePercent ensures the code is between 0-16 inclusive
 func ValidateCode(c int64) (code uint32, err error) {
 	if c < 0 || c > 16 {
@@ -31,3 +76,29 @@ func ValidateCode(c int64) (code uint32, err error) {
 	}
 	return uint32(c), nil
 }
+
+// ValidateScope ensures the scope is one of global, method, peer, identity, or session
+func ValidateScope(scope string) (scopeLower string, err error) {
+	scopeLower = strings.ToLower(scope)
+	switch scopeLower {
+	case "global", "method", "peer", "identity", "session":
+		return scopeLower, nil
+	}
+	return "", errInvalidScope
+}
+
+// ValidateDelay ensures the delay is between 0 and 5 minutes inclusive
+func ValidateDelay(delay time.Duration) (d time.Duration, err error) {
+	if delay < 0 || delay > 5*time.Minute {
+		return d, errInvalidDelay
+	}
+	return delay, nil
+}
+
+// ValidateHops ensures the hop count is between 0-32 inclusive
+func ValidateHops(hops int64) (hopsInt int, err error) {
+	if hops < 0 || hops > 32 {
+		return hopsInt, errInvalidHops
+	}
+	return int(hops), nil
+}
@@ -1,6 +1,9 @@
 package validate
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestValidateModulus(t *testing.T) {
 	tests := []struct {
@@ -57,6 +60,102 @@ func TestValidatePercent(t *testing.T) {
 	}
 }
 
+func TestValidatePPM(t *testing.T) {
+	tests := []struct {
+		name      string
+		ppm       int64
+		expectErr bool
+	}{
+		{"Valid, low ppm", 1, false},
+		{"Valid, 0.01% ppm", 100, false},
+		{"Valid, 1% ppm", 10000, false},
+		{"Valid, high ppm", 1000000, false},
+		{"Invalid, negative ppm", -10, true},
+		{"Invalid, low ppm", 0, true},
+		{"Invalid, over 1000000 ppm", 1000001, true},
+		{"Invalid, over 1000000 ppm", 100000000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidatePPM(tt.ppm)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestValidateRatePPM(t *testing.T) {
+	tests := []struct {
+		name      string
+		ppm       int64
+		expectErr bool
+	}{
+		{"Valid, zero ppm", 0, false},
+		{"Valid, low ppm", 1, false},
+		{"Valid, high ppm", 1000000, false},
+		{"Invalid, negative ppm", -1, true},
+		{"Invalid, over 1000000 ppm", 1000001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateRatePPM(tt.ppm)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestValidateOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		offset    int64
+		expectErr bool
+	}{
+		{"Valid, zero offset", 0, false},
+		{"Valid, low offset", 1, false},
+		{"Valid, high offset", 1000000, false},
+		{"Invalid, negative offset", -1, true},
+		{"Invalid, over 1000000 offset", 1000001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateOffset(tt.offset)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestValidateFirst(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     int64
+		expectErr bool
+	}{
+		{"Valid, low first", 1, false},
+		{"Valid, mid first", 50, false},
+		{"Valid, high first", 1000000, false},
+		{"Invalid, zero first", 0, true},
+		{"Invalid, negative first", -1, true},
+		{"Invalid, over 1000000 first", 1000001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateFirst(tt.first)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
 func TestValidateCode(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -84,3 +183,74 @@ func TestValidateCode(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     string
+		expectErr bool
+	}{
+		{"Valid, global", "global", false},
+		{"Valid, method", "method", false},
+		{"Valid, peer", "peer", false},
+		{"Valid, identity", "identity", false},
+		{"Valid, Session upper case", "Session", false},
+		{"Invalid, empty", "", true},
+		{"Invalid, blah", "blah", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateScope(tt.scope)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestValidateDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		expectErr bool
+	}{
+		{"Valid, zero delay", 0, false},
+		{"Valid, 100ms delay", 100 * time.Millisecond, false},
+		{"Valid, 5m delay", 5 * time.Minute, false},
+		{"Invalid, negative delay", -time.Millisecond, true},
+		{"Invalid, over 5m delay", 5*time.Minute + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateDelay(tt.delay)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestValidateHops(t *testing.T) {
+	tests := []struct {
+		name      string
+		hops      int64
+		expectErr bool
+	}{
+		{"Valid, zero hops", 0, false},
+		{"Valid, 2 hops", 2, false},
+		{"Valid, 32 hops", 32, false},
+		{"Invalid, negative hops", -1, true},
+		{"Invalid, 33 hops", 33, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateHops(tt.hops)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+		})
+	}
+}
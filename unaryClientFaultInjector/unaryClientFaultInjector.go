@@ -1,3 +1,5 @@
+//go:build !nofaultinjection
+
 package unaryClientFaultInjector
 
 import (
@@ -6,29 +8,48 @@ import (
 	"log"
 	"os"
 	"strconv"
-	"sync"
 	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/randomizedcoder/grpcFaultInjection/internal/counters"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/markov"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/pattern"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
 	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
 )
 
 const (
 	faultmodulusHeader = "faultmodulus"
 	faultpercentHeader = "faultpercent"
+	faultppmHeader     = "faultppm"
+	faultfirstHeader   = "faultfirst"
+	faultoffsetHeader  = "faultoffset"
 	faultcodesHeader   = "faultcodes"
+
+	faultsequenceHeader = "faultsequence"
+	faultrepeatHeader   = "faultrepeat"
+	faultsessionHeader  = "faultsession"
+	faultscopeHeader    = "faultscope"
+	faultdelayHeader    = "faultdelay"
+	faultmarkovHeader   = "faultmarkov"
+	faultrampHeader     = "faultramp"
+	faulttargetHeader   = "faulttarget"
+
+	faultconnectionHeader = "faultconnection"
+	faultcorruptHeader    = "faultcorrupt"
+	faultpanicHeader      = "faultpanic"
+	faultcancelHeader     = "faultcancel"
 )
 
 var (
-	count   atomic.Uint64
 	fault   atomic.Uint64
 	success atomic.Uint64
 
-	once        sync.Once
-	configError atomic.Uint64
-
 	logger = log.New(os.Stderr, "", log.Ldate|log.Lmicroseconds)
 )
 
@@ -37,29 +58,65 @@ var (
 // on the GRPC server side, which will randomly inject failures into the GRPC responses
 // this is designed for testing, to allow the client to request failures from the GRPC server
 // ultimately to test the client side error handling behavior
+// The request counter is per interceptor, so the Modulus and First patterns have
+// a predictable phase, even when a process creates many interceptors
+// The Sequence position is per interceptor, and per method
+// The Markov state is per interceptor
+// The Ramp starts on the first request of the interceptor
+// The config is checked once per interceptor, and an invalid config returns a config error for every request
 // https://pkg.go.dev/google.golang.org/grpc?utm_source=godoc#UnaryClientInterceptor
 func UnaryClientFaultInjector(config UnaryClientInterceptorConfig, debugLevel int) grpc.UnaryClientInterceptor {
+
+	var count atomic.Uint64
+
+	// CheckConfig is called here, so each interceptor in the process is checked
+	configErr := CheckConfig(config)
+	if configErr != nil {
+		logger.Printf("CheckConfig(config) fails:%v", configErr)
+	}
+	var configErrors atomic.Uint64
+
+	// a Sequence error fails this interceptor's CheckConfig above, so every request returns the config error
+	steps, _ := sequence.Parse(config.Client.Sequence)
+	positions := counters.NewKeyed(0, 0)
+
+	// a Markov error fails this interceptor's CheckConfig above, so every request returns the config error
+	params, _ := markov.Parse(config.Client.Markov)
+	var state atomic.Uint64
+
+	// a Ramp error fails this interceptor's CheckConfig above, so every request returns the config error
+	profile, _ := ramp.Parse(config.Client.Ramp)
+	var rampStart atomic.Uint64
+
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
 		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 
-		counter := count.Add(1)
+		if !faultSwitch.Enabled() {
+			disabled.Add(1)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
 
-		once.Do(func() {
-			err := CheckConfig(config)
-			if err != nil {
-				configError.Add(1)
-				log.Print("checkConfig(config) fails")
-			}
-		})
+		counter := count.Add(1)
 
-		if configError.Load() > 0 {
-			c := configError.Add(1)
+		if configErr != nil {
+			c := configErrors.Add(1)
 			return fmt.Errorf("config error:%d", c)
 		}
 
+		if config.Scenario != nil {
+			outMD, _ := metadata.FromOutgoingContext(ctx)
+			d := config.Scenario.Evaluate(method, outMD, nil)
+			if d.Matched {
+				return scenarioInject(ctx, config, d, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+			if config.Client == (ModeValue{}) {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+		}
+
 		switch config.Client.Mode {
 		case Modulus:
-			if counter%uint64(config.Client.Value) == 0 {
+			if pattern.Modulus(counter, uint64(config.Client.Value), uint64(config.Client.Offset)) {
 
 				if debugLevel > 10 {
 					logger.Printf("UnaryClientFaultInjector counter:%d", counter)
@@ -70,15 +127,42 @@ func UnaryClientFaultInjector(config UnaryClientInterceptorConfig, debugLevel in
 			return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
 
 		case Percent:
-			if config.Client.Value == 100 {
-				return faultInject(ctx, config, debugLevel, method, req, reply, cc, invoker, opts...)
+			if !rand.SamplePercent(config.Client.Value) {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+
+		case PPM:
+			if !rand.SamplePPM(config.Client.Value) {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+
+		case First:
+			if !pattern.First(counter, uint64(config.Client.Value), uint64(config.Client.Offset)) {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+
+		case Sequence:
+			step := sequence.At(steps, positions.Add(method), config.Client.Repeat)
+			if !step.Fault {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
 			}
+			// the sequence step code replaces the configured Codes
+			stepConfig := config
+			stepConfig.Codes = strconv.FormatInt(int64(step.Code), 10)
+			return faultInject(ctx, stepConfig, debugLevel, method, req, reply, cc, invoker, opts...)
 
-			if rand.FastRandNInt() > config.Client.Value {
+		case Markov:
+			if fault, _ := markov.Step(&state, params); !fault {
 				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
 			}
+
+		case Ramp:
+			if !rand.SamplePPM(rampRate(&rampStart, profile, time.Now())) {
+				return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+			}
+
 		default:
-			return fmt.Errorf("config error: must have modulus or percent")
+			return fmt.Errorf("config error: must have modulus, percent, ppm, first, sequence, markov or ramp")
 		}
 
 		return faultInject(ctx, config, debugLevel, method, req, reply, cc, invoker, opts...)
@@ -98,9 +182,31 @@ func noFaultInject(ctx context.Context, debugLevel int, method string, req, repl
 	return invoker(ctx, method, req, reply, cc, opts...)
 }
 
+// dryRunInject logs and counts the fault, which is not injected, and calls the invoker
+func dryRunInject(ctx context.Context, debugLevel int, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	d := dryRun.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("dry run fault method:%s dryRun:%d", method, d)
+	}
+
+	return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+}
+
 func faultInject(ctx context.Context, config UnaryClientInterceptorConfig, debugLevel int,
 	method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 
+	if faultSwitch.DryRun() {
+		return dryRunInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+	}
+
+	if config.Action != ActionServer {
+		return localInject(ctx, config.Action, localCode(config.Codes), config.LocalDelay, localMutations(config.Mutate), debugLevel,
+			method, req, reply, cc, invoker, opts...)
+	}
+
 	f := fault.Add(1)
 	s := success.Load()
 
@@ -121,17 +227,92 @@ func faultInject(ctx context.Context, config UnaryClientInterceptorConfig, debug
 		md = metadata.Pairs(
 			faultpercentHeader, strconv.FormatInt(int64(config.Server.Value), 10),
 		)
+	case PPM:
+		md = metadata.Pairs(
+			faultppmHeader, strconv.FormatInt(int64(config.Server.Value), 10),
+		)
+	case First:
+		md = metadata.Pairs(
+			faultfirstHeader, strconv.FormatInt(int64(config.Server.Value), 10),
+		)
+	case Sequence:
+		md = metadata.Pairs(
+			faultsequenceHeader, config.Server.Sequence,
+		)
+		if config.Server.Repeat {
+			md.Append(faultrepeatHeader, strconv.FormatBool(config.Server.Repeat))
+		}
+	case Markov:
+		md = metadata.Pairs(
+			faultmarkovHeader, config.Server.Markov,
+		)
+	case Ramp:
+		md = metadata.Pairs(
+			faultrampHeader, config.Server.Ramp,
+		)
+	}
+
+	if config.Server.Offset > 0 {
+		md.Append(faultoffsetHeader, strconv.FormatInt(int64(config.Server.Offset), 10))
 	}
 
 	if len(config.Codes) > 0 {
 		md.Append(faultcodesHeader, config.Codes)
 	}
 
+	if len(config.Connection) > 0 {
+		md.Append(faultconnectionHeader, config.Connection)
+	}
+
+	if len(config.Corrupt) > 0 {
+		md.Append(faultcorruptHeader, config.Corrupt)
+	}
+
+	if len(config.Panic) > 0 {
+		md.Append(faultpanicHeader, config.Panic)
+	}
+
+	if len(config.Cancel) > 0 {
+		md.Append(faultcancelHeader, config.Cancel)
+	}
+
+	if len(config.Session) > 0 {
+		md.Append(faultsessionHeader, config.Session)
+	}
+
+	if len(config.Scope) > 0 {
+		md.Append(faultscopeHeader, config.Scope)
+	}
+
+	hop(md, config)
+	sign(md, method, config)
+
 	if debugLevel > 10 {
 		logger.Print("md:", md)
 	}
 
-	ctxMD := metadata.NewOutgoingContext(ctx, md)
+	// join, so the application, and propagated fault, headers are kept
+	outMD, _ := metadata.FromOutgoingContext(ctx)
+	ctxMD := metadata.NewOutgoingContext(ctx, metadata.Join(outMD, md))
 
 	return invoker(ctxMD, method, req, reply, cc, opts...)
 }
+
+// hop adds the "faulttarget" and "faulthops" headers, for a fault at a downstream service
+func hop(md metadata.MD, config UnaryClientInterceptorConfig) {
+	if len(config.Target) > 0 {
+		md.Set(faulttargetHeader, config.Target)
+	}
+	if config.Hops > 0 {
+		md.Set(signature.HopsHeader, strconv.Itoa(config.Hops))
+	}
+}
+
+// sign adds the "faultexpiry" and "faultsignature" headers, if there is a Secret
+// The signature is bound to the method, or the Target
+func sign(md metadata.MD, method string, config UnaryClientInterceptorConfig) {
+	if len(config.Secret) == 0 {
+		return
+	}
+	signature.Sign(md, method, config.Secret, config.SignatureTTL, time.Now())
+}
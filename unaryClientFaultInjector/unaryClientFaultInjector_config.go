@@ -1,5 +1,9 @@
 package unaryClientFaultInjector
 
+// This .go file holds the configuration types, which are built with, and without, the
+// "nofaultinjection" build tag.  UnaryClientInterceptorConfig is in _config_fault.go, and
+// the stub in _config_nofault.go, so the production binaries don't link faultScenario
+
 import (
 	"fmt"
 	"strings"
@@ -8,19 +12,76 @@ import (
 type Mode int32
 
 const (
-	Modulus Mode = iota
-	Percent Mode = 1
+	Modulus  Mode = iota
+	Percent  Mode = 1
+	PPM      Mode = 2
+	First    Mode = 3
+	Sequence Mode = 4
+	Markov   Mode = 5
+	Ramp     Mode = 6
 )
 
+// ModeValue selects which requests fault
+// Offset is optional, and is the request counter the Modulus or First pattern starts at
+// e.g. Mode: Modulus, Value: 2, Offset: 1 faults requests 1,3,5...
+// e.g. Mode: First, Value: 1 faults only the first request, so the retry succeeds
+// e.g. Mode: First, Value: 3, Offset: 10 faults requests 10,11,12
+// Sequence is only used with Mode: Sequence, and is a comma seperated list of "ok" or GRPC status codes
+// e.g. Mode: Sequence, Sequence: "ok,14,14,ok,4,ok"
+// Repeat starts the Sequence again once it is finished, otherwise all the following requests succeed
+// Markov is only used with Mode: Markov, and is the two state ( Gilbert-Elliott ) model for bursts of faults
+// "good to bad, bad to good, good rate, bad rate", all parts-per-million 0-1000000
+// e.g. Mode: Markov, Markov: "10000,200000,0,900000"
+// Ramp is only used with Mode: Ramp, and is the fault percent moving over time "from,to,duration[,shape[,end]]"
+// shape is "linear" ( default ) or "stepN", and end is "hold" ( default ) or "fall"
+// e.g. Mode: Ramp, Ramp: "0,50,5m" or "10,100,10m,step10,fall"
 type ModeValue struct {
-	Mode  Mode
-	Value int
+	Mode     Mode
+	Value    int
+	Offset   int
+	Sequence string
+	Repeat   bool
+	Markov   string
+	Ramp     string
+}
+
+// Action is where the fault is injected
+type Action int32
+
+const (
+	ActionServer Action = iota
+	ActionError  Action = 1
+	ActionDelay  Action = 2
+	ActionMutate Action = 3
+)
+
+func StringToAction(str string) (action Action) {
+	switch strings.ToLower(str) {
+	case "server":
+		action = ActionServer
+	case "e", "error":
+		action = ActionError
+	case "d", "delay":
+		action = ActionDelay
+	case "m", "mutate":
+		action = ActionMutate
+	}
+	return action
 }
 
-type UnaryClientInterceptorConfig struct {
-	Client ModeValue
-	Server ModeValue
-	Codes  string
+// Stats are the client interceptor counters, which are shared by all the client interceptors
+// RampPPM is the most recent Client.Mode Ramp fault rate, in parts-per-million ( 10000 = 1% )
+// Disabled is the requests passed through while disabled, and DryRun is the faults not injected in dry run mode
+// Local is the faults injected in the client, by ActionError, ActionDelay, or ActionMutate, which are also counted in Faults
+// Mutated is the requests mutated by ActionMutate
+type Stats struct {
+	Success  uint64
+	Faults   uint64
+	RampPPM  int64
+	Disabled uint64
+	DryRun   uint64
+	Local    uint64
+	Mutated  uint64
 }
 
 func (m Mode) toString() {
@@ -29,6 +90,16 @@ func (m Mode) toString() {
 		fmt.Println("Modulus")
 	case Percent:
 		fmt.Println("Percent")
+	case PPM:
+		fmt.Println("PPM")
+	case First:
+		fmt.Println("First")
+	case Sequence:
+		fmt.Println("Sequence")
+	case Markov:
+		fmt.Println("Markov")
+	case Ramp:
+		fmt.Println("Ramp")
 	default:
 		fmt.Println("Invalid Mode")
 	}
@@ -48,6 +119,28 @@ func StringToMode(str string) (mode Mode) {
 		mode = Percent
 	case "percent":
 		mode = Percent
+	case "ppm":
+		mode = PPM
+	case "partspermillion":
+		mode = PPM
+	case "f":
+		mode = First
+	case "first":
+		mode = First
+	case "s":
+		mode = Sequence
+	case "seq":
+		mode = Sequence
+	case "sequence":
+		mode = Sequence
+	case "mk":
+		mode = Markov
+	case "markov":
+		mode = Markov
+	case "r":
+		mode = Ramp
+	case "ramp":
+		mode = Ramp
 		//default:
 	}
 	return mode
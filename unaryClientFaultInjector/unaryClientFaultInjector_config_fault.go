@@ -0,0 +1,54 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+)
+
+// Session is optional, and is sent in the "faultsession" header, so the server
+// keeps a seperate sequence position for this client
+// Scope is optional, and is sent in the "faultscope" header, to select which requests
+// share the server request counter. "global", "method", "peer", "identity", or "session"
+// Scenario is optional, and the first matching rule decides if the request asks the server
+// for a fault.  Requests which don't match any rule use the Client and Server ModeValues,
+// or if the Client ModeValue is not set, are not faulted
+// Secret is optional, and signs the fault headers for a server which requires signatures
+// SignatureTTL is how long the signature is valid, and zero (0) is 1 minute
+// Target is optional, and is sent in the "faulttarget" header, so only the named downstream service faults
+// Hops is optional, and is sent in the "faulthops" header, so the fault fires that many service hops downstream
+// Connection is optional, and is sent in the "faultconnection" header, instead of the fault codes,
+// so the server closes the connection "close", resets it "reset", or drains "goaway"
+// Corrupt is optional, and is sent in the "faultcorrupt" header, instead of the fault codes,
+// so the server corrupts the response, e.g. "zero,truncate:message"
+// Panic is optional, and is sent in the "faultpanic" header, instead of the fault codes,
+// so the server panics with the value, to test the server recovery interceptor
+// Cancel is optional, and is sent in the "faultcancel" header, instead of the fault codes,
+// so the server cancels the handler context after the duration, e.g. "0s" is immediately
+// Action is where the fault is injected.  ActionServer asks the server with the fault headers ( the default ),
+// ActionError returns the error in the client, without calling the server, and ActionDelay waits for the LocalDelay,
+// and then calls the server.  ActionError and ActionDelay work with any server, as the server interceptor isn't needed
+// ActionMutate clones the request, and mutates the copy with Mutate, and then calls the server, to test the
+// server input validation, e.g. "drop:id,unknown,oversize:name,negative" ( see internal/mutate )
+// LocalDelay is waited before ActionError, ActionDelay, or ActionMutate.  With a Scenario, the rule is applied in the client
+type UnaryClientInterceptorConfig struct {
+	Client       ModeValue
+	Server       ModeValue
+	Codes        string
+	Session      string
+	Scope        string
+	Scenario     *faultScenario.Scenario
+	Secret       []byte
+	SignatureTTL time.Duration
+	Target       string
+	Hops         int
+	Connection   string
+	Corrupt      string
+	Panic        string
+	Cancel       string
+	Mutate       string
+	Action       Action
+	LocalDelay   time.Duration
+}
@@ -0,0 +1,30 @@
+//go:build nofaultinjection
+
+package unaryClientFaultInjector
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// The config has no Scenario, so the faultScenario package isn't linked
+
+import (
+	"time"
+)
+
+// UnaryClientInterceptorConfig is the optional client configuration, which is ignored
+type UnaryClientInterceptorConfig struct {
+	Client       ModeValue
+	Server       ModeValue
+	Codes        string
+	Session      string
+	Scope        string
+	Secret       []byte
+	SignatureTTL time.Duration
+	Target       string
+	Hops         int
+	Connection   string
+	Corrupt      string
+	Panic        string
+	Cancel       string
+	Mutate       string
+	Action       Action
+	LocalDelay   time.Duration
+}
@@ -0,0 +1,64 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/control"
+)
+
+var (
+	// faultSwitch is the kill switch and dry run mode, for all the client interceptors
+	// the initial state is from the GRPC_FAULT_INJECTION environment variable, "on", "off", or "dryrun"
+	faultSwitch = newFaultSwitch()
+
+	// disabled counts the requests passed through while disabled
+	disabled atomic.Uint64
+
+	// dryRun counts the faults which would have been injected in dry run mode
+	dryRun atomic.Uint64
+)
+
+func newFaultSwitch() *control.Switch {
+	s, err := control.FromEnv()
+	if err != nil {
+		logger.Printf("%v, fault injection is disabled", err)
+	}
+	return s
+}
+
+// Enable enables fault injection
+func Enable() {
+	faultSwitch.Enable()
+}
+
+// Disable disables fault injection, so every request is passed straight to the invoker
+// This is checked on every request
+func Disable() {
+	faultSwitch.Disable()
+}
+
+// Enabled returns true if fault injection is enabled
+func Enabled() bool {
+	return faultSwitch.Enabled()
+}
+
+// SetDryRun sets the dry run mode, where faults are logged and counted, but not injected
+// so the blast radius can be previewed before going live
+func SetDryRun(dryRun bool) {
+	faultSwitch.SetDryRun(dryRun)
+}
+
+// DryRun returns true if in dry run mode
+func DryRun() bool {
+	return faultSwitch.DryRun()
+}
+
+// HandleSignals toggles the fault injection on signals, until the context is done
+// SIGUSR1 toggles enabled / disabled, and SIGUSR2 toggles dry run
+// e.g. go unaryClientFaultInjector.HandleSignals(ctx)
+func HandleSignals(ctx context.Context) {
+	faultSwitch.HandleSignals(ctx, logger)
+}
@@ -0,0 +1,90 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
+)
+
+type controlTest struct {
+	name     string
+	enabled  bool
+	dryRun   bool
+	headers  bool
+	secret   []byte
+	signed   bool
+	disabled uint64
+	dryRuns  uint64
+}
+
+// go test -run TestControl -v
+func TestControl(t *testing.T) {
+	tests := []controlTest{
+		{name: "enabled", enabled: true, headers: true},
+		{name: "disabled", enabled: false, headers: false, disabled: 1},
+		{name: "dry run", enabled: true, dryRun: true, headers: false, dryRuns: 1},
+		{name: "signed", enabled: true, headers: true, secret: []byte("secret"), signed: true},
+	}
+
+	defer func() {
+		Enable()
+		SetDryRun(false)
+	}()
+
+	conf := UnaryClientInterceptorConfig{
+		Client: ModeValue{Mode: Modulus, Value: 1},
+		Server: ModeValue{Mode: Modulus, Value: 1},
+		Codes:  "14",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.enabled {
+				Enable()
+			} else {
+				Disable()
+			}
+			SetDryRun(tt.dryRun)
+
+			c := conf
+			c.Secret = tt.secret
+			interceptor := UnaryClientFaultInjector(c, 0)
+
+			var headers, signed bool
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				headers = len(md.Get(faultmodulusHeader)) > 0
+				signed = signature.Verify(md, method, tt.secret, 0, time.Now()) == nil
+				return nil
+			}
+
+			before := GetStats()
+
+			if err := interceptor(context.Background(), "/grpc.examples.echo.Echo/UnaryEcho", "req", nil, nil, invoker); err != nil {
+				t.Fatalf("test: %s, error:%v", tt.name, err)
+			}
+			if headers != tt.headers {
+				t.Errorf("test: %s, headers:%t != tt.headers:%t", tt.name, headers, tt.headers)
+			}
+			if headers && signed != tt.signed {
+				t.Errorf("test: %s, signed:%t != tt.signed:%t", tt.name, signed, tt.signed)
+			}
+
+			after := GetStats()
+			if d := after.Disabled - before.Disabled; d != tt.disabled {
+				t.Errorf("test: %s, disabled:%d != tt.disabled:%d", tt.name, d, tt.disabled)
+			}
+			if d := after.DryRun - before.DryRun; d != tt.dryRuns {
+				t.Errorf("test: %s, dryRun:%d != tt.dryRuns:%d", tt.name, d, tt.dryRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+)
+
+// localInject injects the fault in the client, without the server interceptor
+// The delay is waited first, then ActionError returns the error without calling the invoker,
+// ActionDelay calls the invoker without any fault headers, and ActionMutate calls the invoker
+// with a mutated copy of the request
+func localInject(ctx context.Context, action Action, code codes.Code, d time.Duration, ms []mutate.Mutation, debugLevel int,
+	method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	f := fault.Add(1)
+	s := success.Load()
+	l := local.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("localInject method:%s action:%d code:%d delay:%s local:%d", method, action, uint32(code), d, l)
+		logger.Print(logFaultRequest(s, f))
+	}
+
+	if d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	switch action {
+	case ActionError:
+		// the code zero (0) is an empty response
+		if code == codes.OK {
+			if m, ok := reply.(proto.Message); ok {
+				proto.Reset(m)
+			}
+			return nil
+		}
+		return status.Errorf(code, "client fault code:%d success:%d fault:%d", uint32(code), s, f)
+	case ActionMutate:
+		req = mutateRequest(req, ms, debugLevel)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// mutateRequest returns a mutated copy of the request, so the caller's request isn't changed
+// A request which isn't a protobuf message is returned unchanged
+func mutateRequest(req interface{}, ms []mutate.Mutation, debugLevel int) interface{} {
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		if debugLevel > 10 {
+			logger.Printf("mutateRequest request:%T isn't a protobuf message", req)
+		}
+		return req
+	}
+
+	msg = proto.Clone(msg)
+	changed := mutate.Apply(msg, ms)
+	m := mutated.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("mutateRequest mutations:%s changed:%d mutated:%d", mutate.String(ms), changed, m)
+	}
+
+	return msg
+}
+
+// localMutations returns the Mutate mutations, which are checked by CheckConfig
+func localMutations(str string) []mutate.Mutation {
+	ms, _ := mutate.Parse(str)
+	return ms
+}
+
+// localCode returns one of the comma seperated codes, or a random fault code if there are none
+// The codes are checked by CheckConfig
+func localCode(cs string) codes.Code {
+
+	if len(cs) == 0 {
+		return rand.RandomFaultCode()
+	}
+
+	var faultCodes []codes.Code
+	for _, p := range strings.Split(cs, ",") {
+		c, err := strconv.ParseInt(p, 0, 64)
+		if err != nil {
+			continue
+		}
+		faultCodes = append(faultCodes, codes.Code(c))
+	}
+
+	switch len(faultCodes) {
+	case 0:
+		return rand.RandomFaultCode()
+	case 1:
+		return faultCodes[0]
+	default:
+		return rand.RandomSuppliedFaultCode(&faultCodes)
+	}
+}
@@ -0,0 +1,213 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+)
+
+type localTest struct {
+	name       string
+	action     Action
+	codes      string
+	localDelay time.Duration
+	code       codes.Code
+	invoked    bool
+	headers    bool
+	minTime    time.Duration
+	local      uint64
+}
+
+// go test -run TestLocal -v
+func TestLocal(t *testing.T) {
+	tests := []localTest{
+		{
+			name:    "server, fault headers sent",
+			action:  ActionServer,
+			code:    codes.OK,
+			invoked: true,
+			headers: true,
+		},
+		{
+			name:    "error, invoker not called",
+			action:  ActionError,
+			code:    codes.Unavailable,
+			invoked: false,
+			local:   1,
+		},
+		{
+			name:    "error code zero, empty response",
+			action:  ActionError,
+			codes:   "0",
+			code:    codes.OK,
+			invoked: false,
+			local:   1,
+		},
+		{
+			name:       "error after delay",
+			action:     ActionError,
+			localDelay: 20 * time.Millisecond,
+			code:       codes.Unavailable,
+			invoked:    false,
+			minTime:    20 * time.Millisecond,
+			local:      1,
+		},
+		{
+			name:       "delay, then invoked without fault headers",
+			action:     ActionDelay,
+			localDelay: 20 * time.Millisecond,
+			code:       codes.OK,
+			invoked:    true,
+			minTime:    20 * time.Millisecond,
+			local:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			conf := UnaryClientInterceptorConfig{
+				Client:     ModeValue{Mode: Modulus, Value: 1},
+				Server:     ModeValue{Mode: Modulus, Value: 1},
+				Codes:      "14",
+				Action:     tt.action,
+				LocalDelay: tt.localDelay,
+			}
+			if tt.codes != "" {
+				conf.Codes = tt.codes
+			}
+			if err := CheckConfig(conf); err != nil {
+				t.Fatalf("test: %s, CheckConfig error:%v", tt.name, err)
+			}
+			interceptor := UnaryClientFaultInjector(conf, 0)
+
+			var invoked, headers bool
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				invoked = true
+				md, _ := metadata.FromOutgoingContext(ctx)
+				headers = len(md.Get(faultmodulusHeader)) > 0
+				return nil
+			}
+
+			before := GetStats()
+			start := time.Now()
+
+			reply := &descriptorpb.FieldDescriptorProto{Name: proto.String("reply")}
+			err := interceptor(context.Background(), "/grpc.examples.echo.Echo/UnaryEcho", "req", reply, nil, invoker)
+
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("test: %s, code:%s != tt.code:%s", tt.name, code, tt.code)
+			}
+			if invoked != tt.invoked {
+				t.Errorf("test: %s, invoked:%t != tt.invoked:%t", tt.name, invoked, tt.invoked)
+			}
+			if headers != tt.headers {
+				t.Errorf("test: %s, headers:%t != tt.headers:%t", tt.name, headers, tt.headers)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minTime {
+				t.Errorf("test: %s, elapsed:%s < tt.minTime:%s", tt.name, elapsed, tt.minTime)
+			}
+			if l := GetStats().Local - before.Local; l != tt.local {
+				t.Errorf("test: %s, local:%d != tt.local:%d", tt.name, l, tt.local)
+			}
+			if empty := reply.Name == nil; empty != (tt.codes == "0") {
+				t.Errorf("test: %s, reply:%v", tt.name, reply)
+			}
+		})
+	}
+}
+
+type mutateTest struct {
+	name    string
+	mutate  string
+	req     interface{}
+	check   func(req interface{}) bool
+	mutated uint64
+}
+
+// go test -run TestMutate -v
+func TestMutate(t *testing.T) {
+	tests := []mutateTest{
+		{
+			name:   "drop and negative",
+			mutate: "drop:name,negative:number",
+			req:    &descriptorpb.FieldDescriptorProto{Name: proto.String("id"), Number: proto.Int32(1)},
+			check: func(req interface{}) bool {
+				r := req.(*descriptorpb.FieldDescriptorProto)
+				return r.Name == nil && r.GetNumber() == -1
+			},
+			mutated: 1,
+		},
+		{
+			name:   "oversize",
+			mutate: "oversize:json_name",
+			req:    &descriptorpb.FieldDescriptorProto{Name: proto.String("id")},
+			check: func(req interface{}) bool {
+				r := req.(*descriptorpb.FieldDescriptorProto)
+				return len(r.GetJsonName()) == mutate.OversizeLength && r.GetName() == "id"
+			},
+			mutated: 1,
+		},
+		{
+			name:   "not a protobuf message, unchanged",
+			mutate: "drop",
+			req:    "req",
+			check: func(req interface{}) bool {
+				return req == "req"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			conf := UnaryClientInterceptorConfig{
+				Client: ModeValue{Mode: Modulus, Value: 1},
+				Action: ActionMutate,
+				Mutate: tt.mutate,
+			}
+			if err := CheckConfig(conf); err != nil {
+				t.Fatalf("test: %s, CheckConfig error:%v", tt.name, err)
+			}
+			interceptor := UnaryClientFaultInjector(conf, 0)
+
+			var sent interface{}
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				sent = req
+				return nil
+			}
+
+			var original interface{} = tt.req
+			if m, ok := tt.req.(proto.Message); ok {
+				original = proto.Clone(m)
+			}
+
+			before := GetStats()
+
+			if err := interceptor(context.Background(), "/grpc.examples.echo.Echo/UnaryEcho", tt.req, nil, nil, invoker); err != nil {
+				t.Fatalf("test: %s, err:%v", tt.name, err)
+			}
+
+			if !tt.check(sent) {
+				t.Errorf("test: %s, unexpected request sent:%v", tt.name, sent)
+			}
+			if m, ok := tt.req.(proto.Message); ok && !proto.Equal(m, original.(proto.Message)) {
+				t.Errorf("test: %s, the caller's request was changed:%v", tt.name, m)
+			}
+			if m := GetStats().Mutated - before.Mutated; m != tt.mutated {
+				t.Errorf("test: %s, mutated:%d != tt.mutated:%d", tt.name, m, tt.mutated)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build nofaultinjection
+
+package unaryClientFaultInjector
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// The interceptor passes every request straight to the invoker, and none of the fault
+// injection code is compiled in, so no fault headers are sent
+//
+// go build -tags nofaultinjection
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryClientFaultInjector passes every request to the invoker, and the config is ignored
+func UnaryClientFaultInjector(config UnaryClientInterceptorConfig, debugLevel int) grpc.UnaryClientInterceptor {
+	return passThrough
+}
+
+func passThrough(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// Enable does nothing, fault injection is not compiled in
+func Enable() {}
+
+// Disable does nothing, fault injection is not compiled in
+func Disable() {}
+
+// Enabled is always false
+func Enabled() bool {
+	return false
+}
+
+// SetDryRun does nothing, fault injection is not compiled in
+func SetDryRun(dryRun bool) {}
+
+// DryRun is always false
+func DryRun() bool {
+	return false
+}
+
+// HandleSignals returns immediately, so the signals keep their default behavior
+func HandleSignals(ctx context.Context) {}
+
+// GetStats is always zero
+func GetStats() Stats {
+	return Stats{}
+}
+
+// CheckConfig always returns nil, the config is ignored
+func CheckConfig(config UnaryClientInterceptorConfig) error {
+	return nil
+}
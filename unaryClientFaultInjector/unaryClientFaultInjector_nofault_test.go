@@ -0,0 +1,44 @@
+//go:build nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// go test -tags nofaultinjection -run TestNoFault -v
+func TestNoFault(t *testing.T) {
+
+	conf := UnaryClientInterceptorConfig{
+		Client: ModeValue{Mode: Modulus, Value: 1},
+		Server: ModeValue{Mode: Modulus, Value: 1},
+		Codes:  "14",
+	}
+	interceptor := UnaryClientFaultInjector(conf, 11)
+
+	var headers bool
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		headers = len(md) > 0
+		return nil
+	}
+
+	if err := interceptor(context.Background(), "/grpc.examples.echo.Echo/UnaryEcho", "req", nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error:%v", err)
+	}
+	if headers {
+		t.Error("fault headers were sent")
+	}
+
+	Enable()
+	if Enabled() {
+		t.Error("Enabled() is true")
+	}
+	if s := GetStats(); s != (Stats{}) {
+		t.Errorf("stats:%+v are not zero", s)
+	}
+}
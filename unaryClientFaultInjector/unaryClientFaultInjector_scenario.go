@@ -0,0 +1,104 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+)
+
+// scenarioInject sends the decision of the matching scenario rule to the server
+// a fault is sent as "faultmodulus: 1" with the rule code in "faultcodes",
+// a delay is sent as "faultdelay", which without "faultcodes" only delays, a corruption is sent as "faultcorrupt", a panic as "faultpanic",
+// and a cancel as "faultcancel"
+// Rule trailers are only supported on the server
+func scenarioInject(ctx context.Context, config UnaryClientInterceptorConfig, d faultScenario.Decision, debugLevel int,
+	method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	if debugLevel > 10 {
+		logger.Printf("scenarioInject rule:%q counter:%d fault:%t delay:%s", d.Rule, d.Counter, d.Fault, d.Delay)
+	}
+
+	md := scenarioMD(d)
+	if md == nil {
+		return noFaultInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+	}
+
+	if faultSwitch.DryRun() {
+		return dryRunInject(ctx, debugLevel, method, req, reply, cc, invoker, opts...)
+	}
+
+	// the scenario delay is waited in the client, and the scenario fault is returned in the client,
+	// or with ActionMutate, the scenario fault is the mutated request
+	if config.Action != ActionServer {
+		action := ActionError
+		if config.Action == ActionMutate {
+			action = ActionMutate
+		}
+		if !d.Fault {
+			action = ActionDelay
+		}
+		return localInject(ctx, action, d.Code, d.Delay, localMutations(config.Mutate), debugLevel,
+			method, req, reply, cc, invoker, opts...)
+	}
+
+	if d.Fault {
+		f := fault.Add(1)
+		s := success.Load()
+		if debugLevel > 10 {
+			logger.Print(logFaultRequest(s, f))
+		}
+	} else {
+		success.Add(1)
+	}
+
+	// only the fault headers are signed, before joining the application headers
+	hop(md, config)
+	sign(md, method, config)
+
+	if debugLevel > 10 {
+		logger.Print("md:", md)
+	}
+
+	// join with any existing outgoing metadata, so the application headers are kept
+	outMD, _ := metadata.FromOutgoingContext(ctx)
+	ctxMD := metadata.NewOutgoingContext(ctx, metadata.Join(outMD, md))
+
+	return invoker(ctxMD, method, req, reply, cc, opts...)
+}
+
+// scenarioMD returns the fault headers for the decision, or nil if there
+// is no fault and no delay
+func scenarioMD(d faultScenario.Decision) metadata.MD {
+
+	if !d.Fault && d.Delay == 0 {
+		return nil
+	}
+
+	md := metadata.Pairs(faultmodulusHeader, "1")
+
+	if d.Fault {
+		md.Append(faultcodesHeader, strconv.FormatInt(int64(d.Code), 10))
+		if len(d.Corrupt) > 0 {
+			md.Append(faultcorruptHeader, mutate.String(d.Corrupt))
+		}
+		if len(d.Panic) > 0 {
+			md.Append(faultpanicHeader, d.Panic)
+		}
+		if d.Cancel {
+			md.Append(faultcancelHeader, d.CancelAfter.String())
+		}
+	}
+
+	if d.Delay > 0 {
+		md.Append(faultdelayHeader, d.Delay.String())
+	}
+
+	return md
+}
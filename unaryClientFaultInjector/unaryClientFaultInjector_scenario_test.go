@@ -0,0 +1,109 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+)
+
+type scenarioMDTest struct {
+	name string
+	d    faultScenario.Decision
+	md   metadata.MD
+}
+
+// go test -run TestScenarioMD -v
+func TestScenarioMD(t *testing.T) {
+	tests := []scenarioMDTest{
+		{
+			name: "matched, no fault",
+			d:    faultScenario.Decision{Matched: true},
+			md:   nil,
+		},
+		{
+			name: "fault 14",
+			d:    faultScenario.Decision{Matched: true, Fault: true, Code: codes.Unavailable},
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14"),
+		},
+		{
+			name: "delay only",
+			d:    faultScenario.Decision{Matched: true, Delay: 100 * time.Millisecond},
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultdelayHeader, "100ms"),
+		},
+		{
+			name: "fault 10 and delay",
+			d:    faultScenario.Decision{Matched: true, Fault: true, Code: codes.Aborted, Delay: time.Second},
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "10", faultdelayHeader, "1s"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := scenarioMD(tt.d)
+			if !reflect.DeepEqual(md, tt.md) {
+				t.Errorf("test: %s, md:%v != tt.md:%v", tt.name, md, tt.md)
+			}
+		})
+	}
+}
+
+type joinMetadataTest struct {
+	name string
+	conf UnaryClientInterceptorConfig
+}
+
+// go test -run TestJoinMetadata -v
+func TestJoinMetadata(t *testing.T) {
+	tests := []joinMetadataTest{
+		{
+			name: "modulus",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{Mode: Modulus, Value: 1},
+				Server: ModeValue{Mode: Modulus, Value: 1},
+				Codes:  "14",
+			},
+		},
+		{
+			name: "scenario",
+			conf: UnaryClientInterceptorConfig{
+				Scenario: &faultScenario.Scenario{
+					Rules: []faultScenario.Rule{{Action: faultScenario.Action{Codes: "14"}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var md metadata.MD
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+
+			interceptor := UnaryClientFaultInjector(tt.conf, 0)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "app-header", "kept")
+			if err := interceptor(ctx, "/grpc.examples.echo.Echo/UnaryEcho", "req", "reply", nil, invoker); err != nil {
+				t.Fatalf("test: %s, error:%v", tt.name, err)
+			}
+
+			if v := md.Get("app-header"); len(v) != 1 || v[0] != "kept" {
+				t.Errorf("test: %s, app-header:%v, md:%v", tt.name, v, md)
+			}
+			if v := md.Get(faultcodesHeader); len(v) != 1 || v[0] != "14" {
+				t.Errorf("test: %s, faultcodes:%v, md:%v", tt.name, v, md)
+			}
+		})
+	}
+}
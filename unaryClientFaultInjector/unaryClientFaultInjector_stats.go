@@ -0,0 +1,46 @@
+//go:build !nofaultinjection
+
+package unaryClientFaultInjector
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+)
+
+var (
+	// rampPPM is the most recent Client Ramp fault rate
+	rampPPM atomic.Int64
+
+	// local counts the faults injected in the client, by ActionError, ActionDelay, or ActionMutate
+	local atomic.Uint64
+
+	// mutated counts the requests mutated by ActionMutate
+	mutated atomic.Uint64
+)
+
+// GetStats returns a snapshot of the counters
+func GetStats() Stats {
+	return Stats{
+		Success:  success.Load(),
+		Faults:   fault.Load(),
+		RampPPM:  rampPPM.Load(),
+		Disabled: disabled.Load(),
+		DryRun:   dryRun.Load(),
+		Local:    local.Load(),
+		Mutated:  mutated.Load(),
+	}
+}
+
+// rampRate returns the ramp fault rate, and records it in the stats
+// the ramp starts on the first request, so the start is set with CompareAndSwap
+func rampRate(start *atomic.Uint64, profile ramp.Profile, now time.Time) int {
+
+	start.CompareAndSwap(0, uint64(now.UnixNano()))
+
+	ppm := profile.PPM(now.Sub(time.Unix(0, int64(start.Load()))))
+	rampPPM.Store(int64(ppm))
+
+	return ppm
+}
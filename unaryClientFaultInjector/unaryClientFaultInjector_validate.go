@@ -1,10 +1,18 @@
+//go:build !nofaultinjection
+
 package unaryClientFaultInjector
 
 import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/markov"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
 	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
 )
 
@@ -13,6 +21,44 @@ import (
 // in the GRPC client
 func CheckConfig(config UnaryClientInterceptorConfig) error {
 
+	if _, err := validate.ValidateHops(int64(config.Hops)); err != nil {
+		return fmt.Errorf("ValidateHops config.Hops error: %w", err)
+	}
+
+	// without a Target, the signature is bound to the called method, so it isn't valid downstream
+	if len(config.Secret) > 0 && config.Hops > 0 && config.Target == "" {
+		return fmt.Errorf("config.Target error: must be set for signed Hops")
+	}
+
+	switch config.Action {
+	case ActionServer, ActionError:
+	case ActionDelay:
+		if config.LocalDelay <= 0 {
+			return fmt.Errorf("config.LocalDelay error: must be more than zero for ActionDelay")
+		}
+	case ActionMutate:
+		if _, err := mutate.Parse(config.Mutate); err != nil {
+			return fmt.Errorf("config.Mutate error: %w", err)
+		}
+	default:
+		return fmt.Errorf("config.Action error: must be server, error, delay, or mutate")
+	}
+
+	if _, err := validate.ValidateDelay(config.LocalDelay); err != nil {
+		return fmt.Errorf("ValidateDelay config.LocalDelay error: %w", err)
+	}
+
+	if config.Scenario != nil {
+		// validate a copy, so the scenario rule counters are not reset
+		sc := faultScenario.Scenario{Rules: config.Scenario.Rules}
+		if err := sc.Validate(); err != nil {
+			return fmt.Errorf("config.Scenario error: %w", err)
+		}
+		if config.Client == (ModeValue{}) && config.Server == (ModeValue{}) {
+			return nil
+		}
+	}
+
 	switch config.Client.Mode {
 	case Modulus:
 		if _, err := validate.ValidateModulus(int64(config.Client.Value)); err != nil {
@@ -22,16 +68,67 @@ func CheckConfig(config UnaryClientInterceptorConfig) error {
 		if _, err := validate.ValidatePercent(int64(config.Client.Value)); err != nil {
 			return fmt.Errorf("ValidatePercent config.Client.Value error: %w", err)
 		}
+	case PPM:
+		if _, err := validate.ValidatePPM(int64(config.Client.Value)); err != nil {
+			return fmt.Errorf("ValidatePPM config.Client.Value error: %w", err)
+		}
+	case First:
+		if _, err := validate.ValidateFirst(int64(config.Client.Value)); err != nil {
+			return fmt.Errorf("ValidateFirst config.Client.Value error: %w", err)
+		}
+	case Sequence:
+		if _, err := sequence.Parse(config.Client.Sequence); err != nil {
+			return fmt.Errorf("sequence.Parse config.Client.Sequence error: %w", err)
+		}
+	case Markov:
+		if _, err := markov.Parse(config.Client.Markov); err != nil {
+			return fmt.Errorf("markov.Parse config.Client.Markov error: %w", err)
+		}
+	case Ramp:
+		if _, err := ramp.Parse(config.Client.Ramp); err != nil {
+			return fmt.Errorf("ramp.Parse config.Client.Ramp error: %w", err)
+		}
 	}
 
-	switch config.Server.Mode {
-	case Modulus:
-		if _, err := validate.ValidateModulus(int64(config.Server.Value)); err != nil {
-			return fmt.Errorf("ValidateModulus config.Server.Value error: %w", err)
+	if _, err := validate.ValidateOffset(int64(config.Client.Offset)); err != nil {
+		return fmt.Errorf("ValidateOffset config.Client.Offset error: %w", err)
+	}
+
+	// the Server ModeValue is only sent to the server with ActionServer
+	if config.Action == ActionServer {
+		switch config.Server.Mode {
+		case Modulus:
+			if _, err := validate.ValidateModulus(int64(config.Server.Value)); err != nil {
+				return fmt.Errorf("ValidateModulus config.Server.Value error: %w", err)
+			}
+		case Percent:
+			if _, err := validate.ValidatePercent(int64(config.Server.Value)); err != nil {
+				return fmt.Errorf("ValidatePercent config.Server.Value error: %w", err)
+			}
+		case PPM:
+			if _, err := validate.ValidatePPM(int64(config.Server.Value)); err != nil {
+				return fmt.Errorf("ValidatePPM config.Server.Value error: %w", err)
+			}
+		case First:
+			if _, err := validate.ValidateFirst(int64(config.Server.Value)); err != nil {
+				return fmt.Errorf("ValidateFirst config.Server.Value error: %w", err)
+			}
+		case Sequence:
+			if _, err := sequence.Parse(config.Server.Sequence); err != nil {
+				return fmt.Errorf("sequence.Parse config.Server.Sequence error: %w", err)
+			}
+		case Markov:
+			if _, err := markov.Parse(config.Server.Markov); err != nil {
+				return fmt.Errorf("markov.Parse config.Server.Markov error: %w", err)
+			}
+		case Ramp:
+			if _, err := ramp.Parse(config.Server.Ramp); err != nil {
+				return fmt.Errorf("ramp.Parse config.Server.Ramp error: %w", err)
+			}
 		}
-	case Percent:
-		if _, err := validate.ValidatePercent(int64(config.Server.Value)); err != nil {
-			return fmt.Errorf("ValidatePercent config.Server.Value error: %w", err)
+
+		if _, err := validate.ValidateOffset(int64(config.Server.Offset)); err != nil {
+			return fmt.Errorf("ValidateOffset config.Server.Offset error: %w", err)
 		}
 	}
 
@@ -41,6 +138,34 @@ func CheckConfig(config UnaryClientInterceptorConfig) error {
 		}
 	}
 
+	if len(config.Scope) > 0 {
+		if _, err := validate.ValidateScope(config.Scope); err != nil {
+			return fmt.Errorf("ValidateScope config.Scope error: %w", err)
+		}
+	}
+
+	switch strings.ToLower(config.Connection) {
+	case "", "close", "reset", "goaway":
+	default:
+		return fmt.Errorf("config.Connection error: must be close, reset, or goaway")
+	}
+
+	if len(config.Cancel) > 0 {
+		d, err := time.ParseDuration(config.Cancel)
+		if err != nil {
+			return fmt.Errorf("config.Cancel error: %w", err)
+		}
+		if _, err := validate.ValidateDelay(d); err != nil {
+			return fmt.Errorf("ValidateDelay config.Cancel error: %w", err)
+		}
+	}
+
+	if len(config.Corrupt) > 0 {
+		if _, err := mutate.Parse(config.Corrupt); err != nil {
+			return fmt.Errorf("config.Corrupt error: %w", err)
+		}
+	}
+
 	return nil
 }
 
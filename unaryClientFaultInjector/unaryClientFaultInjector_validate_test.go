@@ -1,6 +1,18 @@
+//go:build !nofaultinjection
+
 package unaryClientFaultInjector
 
-import "testing"
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+)
 
 type CheckConfigTest struct {
 	name      string
@@ -161,6 +173,494 @@ func TestCheckConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "valid, ppm 1",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  PPM,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  PPM,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid, ppm 1000000",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  PPM,
+					Value: 1000000,
+				},
+				Server: ModeValue{
+					Mode:  PPM,
+					Value: 1000000,
+				},
+				Codes: "10",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, ppm 0",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  PPM,
+					Value: 0,
+				},
+				Server: ModeValue{
+					Mode:  PPM,
+					Value: 0,
+				},
+				Codes: "10",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid, ppm 1000001",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 100,
+				},
+				Server: ModeValue{
+					Mode:  PPM,
+					Value: 1000001,
+				},
+				Codes: "10",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, first 1",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  First,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid, modulus 2 offset 1, first 3 offset 10",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:   Modulus,
+					Value:  2,
+					Offset: 1,
+				},
+				Server: ModeValue{
+					Mode:   First,
+					Value:  3,
+					Offset: 10,
+				},
+				Codes: "10",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, first 0",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  First,
+					Value: 0,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid, offset -1",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:   Modulus,
+					Value:  1,
+					Offset: -1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Codes: "10",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, sequence ok,14,14,ok,4,ok",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:     Sequence,
+					Sequence: "ok,14,14,ok,4,ok",
+				},
+				Server: ModeValue{
+					Mode:     Sequence,
+					Sequence: "14,ok",
+					Repeat:   true,
+				},
+				Session: "test1",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, sequence empty",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode: Sequence,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid, server sequence code 17",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:     Sequence,
+					Sequence: "ok,17",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, markov",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:   Markov,
+					Markov: "10000,200000,0,1000000",
+				},
+				Server: ModeValue{
+					Mode:   Markov,
+					Markov: "10000,200000,0,900000",
+				},
+				Codes: "14",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, server markov 3 values",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:   Markov,
+					Markov: "10000,200000,0",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, ramp",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode: Ramp,
+					Ramp: "0,50,5m",
+				},
+				Server: ModeValue{
+					Mode: Ramp,
+					Ramp: "10,100,10m,step10,fall",
+				},
+				Codes: "14",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, client ramp 101",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode: Ramp,
+					Ramp: "0,101,5m",
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, scope session",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 2,
+				},
+				Session: "test1",
+				Scope:   "session",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, scope blah",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 2,
+				},
+				Scope: "blah",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, target and 2 hops",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Target: "C",
+				Hops:   2,
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid, signed hops 2 with target",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Secret: []byte("secret"),
+				Target: "C",
+				Hops:   2,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, signed hops 2 without target",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Secret: []byte("secret"),
+				Hops:   2,
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid, hops 33",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Hops: 33,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, connection goaway",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Connection: "goaway",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, connection blah",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Connection: "blah",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, cancel",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Cancel: "50ms",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, cancel blah",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Cancel: "blah",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, corrupt",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Corrupt: "zero,truncate:message",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, corrupt blah",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Modulus,
+					Value: 10,
+				},
+				Server: ModeValue{
+					Mode:  Modulus,
+					Value: 1,
+				},
+				Corrupt: "blah",
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, action mutate",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 10,
+				},
+				Action: ActionMutate,
+				Mutate: "drop:id,negative",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, action mutate without Mutate",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 10,
+				},
+				Action: ActionMutate,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, action error",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 10,
+				},
+				Codes:  "14",
+				Action: ActionError,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, action delay without delay",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 10,
+				},
+				Action: ActionDelay,
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid, action 3",
+			conf: UnaryClientInterceptorConfig{
+				Client: ModeValue{
+					Mode:  Percent,
+					Value: 10,
+				},
+				Action: 3,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid, scenario only",
+			conf: UnaryClientInterceptorConfig{
+				Scenario: &faultScenario.Scenario{
+					Rules: []faultScenario.Rule{
+						{Action: faultScenario.Action{Codes: "14"}},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid, scenario code 17",
+			conf: UnaryClientInterceptorConfig{
+				Scenario: &faultScenario.Scenario{
+					Rules: []faultScenario.Rule{
+						{Action: faultScenario.Action{Codes: "17"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -224,3 +724,58 @@ func TestValidateCodes(t *testing.T) {
 		})
 	}
 }
+
+type perInterceptorTest struct {
+	name        string
+	conf        UnaryClientInterceptorConfig
+	configError bool
+}
+
+// go test -run TestCheckConfigPerInterceptor -v
+func TestCheckConfigPerInterceptor(t *testing.T) {
+	tests := []perInterceptorTest{
+		{
+			name:        "valid",
+			conf:        UnaryClientInterceptorConfig{Client: ModeValue{Mode: Modulus, Value: 1}, Codes: "14", Action: ActionError},
+			configError: false,
+		},
+		{
+			name:        "modulus zero",
+			conf:        UnaryClientInterceptorConfig{Client: ModeValue{Mode: Modulus, Value: 0}, Codes: "14", Action: ActionError},
+			configError: true,
+		},
+		{
+			name:        "invalid sequence",
+			conf:        UnaryClientInterceptorConfig{Client: ModeValue{Mode: Sequence, Sequence: "ok,blah"}, Codes: "14", Action: ActionError},
+			configError: true,
+		},
+		{
+			name:        "valid after invalid",
+			conf:        UnaryClientInterceptorConfig{Client: ModeValue{Mode: Modulus, Value: 1}, Codes: "14", Action: ActionError},
+			configError: false,
+		},
+	}
+
+	// all the interceptors are built first, so the first one can't hide the config of the others
+	interceptors := make([]grpc.UnaryClientInterceptor, len(tests))
+	for i, tt := range tests {
+		interceptors[i] = UnaryClientFaultInjector(tt.conf, 0)
+	}
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := interceptors[i](context.Background(), "/grpc.examples.echo.Echo/UnaryEcho", nil, nil, nil, invoker)
+			configError := err != nil && strings.HasPrefix(err.Error(), "config error")
+			if configError != tt.configError {
+				t.Fatalf("test: %s, config error:%t != tt.configError:%t, err:%v", tt.name, configError, tt.configError, err)
+			}
+			if !tt.configError && status.Code(err) != codes.Unavailable {
+				t.Errorf("test: %s, code:%s != Unavailable", tt.name, status.Code(err))
+			}
+		})
+	}
+}
@@ -1,19 +1,33 @@
+//go:build !nofaultinjection
+
 package unaryServerFaultInjector
 
 import (
 	"context"
 	"log"
+	"net"
 	"os"
 	"sync/atomic"
+	"time"
 
 	_ "unsafe"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/budget"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/counters"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/markov"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/pattern"
 	"github.com/randomizedcoder/grpcFaultInjection/internal/rand"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
 )
 
 var (
@@ -21,16 +35,47 @@ var (
 	fault   atomic.Uint64
 	success atomic.Uint64
 
-	errMetadata = status.Errorf(codes.InvalidArgument, "error metadata")
+	errMetadata  = status.Errorf(codes.InvalidArgument, "error metadata")
+	errUntrusted = status.Error(codes.PermissionDenied, "untrusted fault headers")
 
 	logger = log.New(os.Stderr, "", log.Ldate|log.Lmicroseconds)
 )
 
+// UnaryServerFaultInjector uses the default configuration, which is a single
+// global request counter
 // https://pkg.go.dev/google.golang.org/grpc?utm_source=godoc#UnaryServerInterceptor
 func UnaryServerFaultInjector(debugLevel int) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{}, debugLevel)
+}
+
+// UnaryServerFaultInjectorWithConfig allows the request counter scope to be configured,
+// so concurrent test clients don't share a request counter
+func UnaryServerFaultInjectorWithConfig(config UnaryServerInterceptorConfig, debugLevel int) grpc.UnaryServerInterceptor {
+
+	// scoped holds the request counters for all scopes except global
+	scoped := counters.NewKeyed(config.MaxCounters, config.CounterTTL)
+
+	// sequences holds the position of each "faultsequence", keyed per method, session and scope
+	sequences := counters.NewKeyed(config.MaxCounters, config.CounterTTL)
+
+	// states holds the good or bad state of each "faultmarkov", keyed per method, session and scope
+	states := counters.NewKeyed(config.MaxCounters, config.CounterTTL)
+
+	// starts holds the start time, in unix nanoseconds, of each "faultramp", keyed per method, session and scope
+	starts := counters.NewKeyed(config.MaxCounters, config.CounterTTL)
 
-		counter := count.Add(1)
+	b := budget.New(budget.Config{
+		MaxFaults:          config.Budget.MaxFaults,
+		FaultsPerSecond:    config.Budget.FaultsPerSecond,
+		Burst:              config.Budget.Burst,
+		MaxFaultsPerCaller: config.Budget.MaxFaultsPerCaller,
+		MaxCallers:         config.MaxCounters,
+		CallerTTL:          config.CounterTTL,
+	})
+
+	intercept := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		globalCounter := count.Add(1)
 
 		// https://grpc.io/docs/guides/metadata/
 		// https://github.com/grpc/grpc-go/blob/master/examples/features/metadata/server/main.go
@@ -39,6 +84,62 @@ func UnaryServerFaultInjector(debugLevel int) grpc.UnaryServerInterceptor {
 			return nil, errMetadata
 		}
 
+		if config.Trust.enabled() && signature.HasFaultHeaders(md) {
+			if err := config.Trust.check(ctx, md, info.FullMethod, time.Now()); err != nil {
+				u := untrusted.Add(1)
+				if u == 1 || debugLevel > 10 {
+					logger.Printf("untrusted fault headers peer:%s error:%v untrusted:%d", peerAddress(ctx), err, u)
+				}
+				if config.Trust.Reject {
+					return nil, errUntrusted
+				}
+				md = stripFaultHeaders(md)
+			}
+		}
+
+		foundHops, faultHops, errH := readFaultHops(&md, debugLevel)
+		if errH != nil {
+			return nil, errH
+		}
+
+		if p := propagateMD(md, foundHops, faultHops); p != nil {
+			ctx = context.WithValue(ctx, propagateKey{}, p)
+		}
+
+		if signature.HasFaultHeaders(md) && !forThisHop(&md, info.FullMethod, config.Service, faultHops) {
+			if debugLevel > 10 {
+				logger.Printf("fault headers for another hop method:%s hops:%d", info.FullMethod, faultHops)
+			}
+			md = stripFaultHeaders(md)
+		}
+
+		if sc := config.scenario(); sc != nil {
+			var peerAddr net.Addr
+			if p, ok := peer.FromContext(ctx); ok {
+				peerAddr = p.Addr
+			}
+			d := sc.Evaluate(info.FullMethod, md, peerAddr)
+			if d.Matched {
+				return scenarioInject(ctx, req, handler, d, debugLevel)
+			}
+		}
+
+		scope := config.Scope
+		foundScope, faultScope, errSc := readFaultScope(&md, debugLevel)
+		if errSc != nil {
+			return nil, errSc
+		}
+		if foundScope {
+			scope = faultScope
+		}
+
+		key := scopeKey(ctx, info, &md, scope)
+
+		counter := globalCounter
+		if scope != ScopeGlobal {
+			counter = scoped.Add(key)
+		}
+
 		var (
 			foundModulus bool
 			faultModulus uint64
@@ -49,8 +150,73 @@ func UnaryServerFaultInjector(debugLevel int) grpc.UnaryServerInterceptor {
 			return nil, errM
 		}
 
+		_, faultOffset, errO := readFaultOffset(&md, debugLevel)
+		if errO != nil {
+			return nil, errO
+		}
+
 		if foundModulus {
-			if counter%faultModulus == 0 {
+			if pattern.Modulus(counter, faultModulus, faultOffset) {
+				return faultInject(counter, &md, debugLevel)
+			}
+			return noFaultInject(ctx, req, handler, debugLevel)
+		}
+
+		foundFirst, faultFirst, errF := readFaultFirst(&md, debugLevel)
+		if errF != nil {
+			return nil, errF
+		}
+
+		if foundFirst {
+			if pattern.First(counter, faultFirst, faultOffset) {
+				return faultInject(counter, &md, debugLevel)
+			}
+			return noFaultInject(ctx, req, handler, debugLevel)
+		}
+
+		foundSequence, script, steps, repeat, errS := readFaultSequence(&md, debugLevel)
+		if errS != nil {
+			return nil, errS
+		}
+
+		if foundSequence {
+			position := sequences.Add(sequenceKey(info.FullMethod, readFaultSession(&md), key, script))
+			step := sequence.At(steps, position, repeat)
+			if step.Fault {
+				return faultInjectCode(counter, step.Code, debugLevel)
+			}
+			return noFaultInject(ctx, req, handler, debugLevel)
+		}
+
+		foundMarkov, params, errMk := readFaultMarkov(&md, debugLevel)
+		if errMk != nil {
+			return nil, errMk
+		}
+
+		if foundMarkov {
+			state := states.Counter(sequenceKey(info.FullMethod, readFaultSession(&md), key, params.String()))
+			fault, bad := markov.Step(state, params)
+			if debugLevel > 11 {
+				logger.Printf("markov counter:%d bad:%t fault:%t", counter, bad, fault)
+			}
+			if fault {
+				return faultInject(counter, &md, debugLevel)
+			}
+			return noFaultInject(ctx, req, handler, debugLevel)
+		}
+
+		foundRamp, rawRamp, profile, errR := readFaultRamp(&md, debugLevel)
+		if errR != nil {
+			return nil, errR
+		}
+
+		if foundRamp {
+			start := starts.Counter(sequenceKey(info.FullMethod, readFaultSession(&md), key, rawRamp))
+			ppm := rampRate(start, profile, time.Now())
+			if debugLevel > 11 {
+				logger.Printf("ramp counter:%d ppm:%d", counter, ppm)
+			}
+			if rand.SamplePPM(ppm) {
 				return faultInject(counter, &md, debugLevel)
 			}
 			return noFaultInject(ctx, req, handler, debugLevel)
@@ -58,6 +224,23 @@ func UnaryServerFaultInjector(debugLevel int) grpc.UnaryServerInterceptor {
 
 		return faultPercentInject(ctx, req, handler, counter, &md, debugLevel)
 	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		if !faultSwitch.Enabled() {
+			disabled.Add(1)
+			return handler(ctx, req)
+		}
+
+		resp, err := intercept(ctx, req, info, handler)
+
+		f, ok := err.(*injectedFault)
+		if !ok {
+			return resp, err
+		}
+
+		return applyFault(ctx, req, handler, f, info.FullMethod, b, config.Connections, config.CancelGrace, debugLevel)
+	}
 }
 
 func noFaultInject(
@@ -93,47 +276,382 @@ func faultPercentInject(
 	}
 
 	if !foundPercent {
+		return faultPPMInject(ctx, req, handler, counter, md, debugLevel)
+	}
+
+	if !rand.SamplePercent(faultPercent) {
 		return noFaultInject(ctx, req, handler, debugLevel)
 	}
 
-	if faultPercent == 100 {
-		return faultInject(counter, md, debugLevel)
+	return faultInject(counter, md, debugLevel)
+}
+
+func faultPPMInject(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	counter uint64,
+	md *metadata.MD,
+	debugLevel int) (any, error) {
+
+	var (
+		foundPPM bool
+		faultPPM int
+		errP     error
+	)
+
+	foundPPM, faultPPM, errP = readFaultPPM(md, debugLevel)
+	if errP != nil {
+		return nil, errP
+	}
+
+	if !foundPPM {
+		return noFaultInject(ctx, req, handler, debugLevel)
 	}
 
-	if rand.FastRandNInt() > faultPercent {
+	if !rand.SamplePPM(faultPPM) {
 		return noFaultInject(ctx, req, handler, debugLevel)
 	}
 
 	return faultInject(counter, md, debugLevel)
 }
 
+// scenarioInject applies the decision of the matching scenario rule
+func scenarioInject(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	d faultScenario.Decision,
+	debugLevel int) (any, error) {
+
+	if debugLevel > 10 {
+		logger.Printf("scenarioInject rule:%q counter:%d fault:%t delay:%s", d.Rule, d.Counter, d.Fault, d.Delay)
+	}
+
+	if !d.Fault && d.Delay == 0 {
+		return noFaultInject(ctx, req, handler, debugLevel)
+	}
+
+	// the delay is applied by applyFault, after the dry run and budget checks
+	if !d.Fault {
+		return nil, &injectedFault{
+			counter:   d.Counter,
+			delay:     d.Delay,
+			delayOnly: true,
+		}
+	}
+
+	return nil, &injectedFault{
+		counter:     d.Counter,
+		delay:       d.Delay,
+		code:        d.Code,
+		trailers:    d.Trailers,
+		corrupt:     d.Corrupt,
+		panicValue:  d.Panic,
+		cancel:      d.Cancel,
+		cancelAfter: d.CancelAfter,
+	}
+}
+
+// delay waits for the duration, or returns the context error if the
+// request is cancelled, or the deadline is exceeded, during the delay
+func delay(ctx context.Context, d time.Duration) error {
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// sequenceKey is the key for the sequence position, the markov state, and the ramp start
+// the script is included, so a new script starts from the beginning
+func sequenceKey(method string, session string, key string, script string) string {
+	return method + "|" + session + "|" + key + "|" + script
+}
+
+// faultInject returns the fault decision for a selected request, which is applied by applyFault
+// The optional "faultdelay" is applied before the fault, and with a delay, but without
+// "faultcodes" or another action, the request is only delayed
 func faultInject(
 	counter uint64, md *metadata.MD, debugLevel int) (any, error) {
 
-	f := fault.Add(1)
-	s := success.Load()
-
 	faultCodes, errC := readFaultCodes(md)
 	if errC != nil {
 		return nil, errC
 	}
 
-	var code codes.Code
+	foundDelay, faultDelay, errD := readFaultDelay(md, debugLevel)
+	if errD != nil {
+		return nil, errD
+	}
+
+	inj := &injectedFault{
+		counter: counter,
+		delay:   faultDelay,
+	}
+
+	foundConnection, faultConnection, errCn := readFaultConnection(md, debugLevel)
+	if errCn != nil {
+		return nil, errCn
+	}
+	if foundConnection {
+		inj.code = codes.Unavailable
+		inj.connection = faultConnection
+		return nil, inj
+	}
+
+	foundCancel, after, errCa := readFaultCancel(md, debugLevel)
+	if errCa != nil {
+		return nil, errCa
+	}
+	if foundCancel {
+		inj.cancel = true
+		inj.cancelAfter = after
+		return nil, inj
+	}
+
+	if foundPanic, value := readFaultPanic(md, debugLevel); foundPanic {
+		inj.panicValue = value
+		return nil, inj
+	}
+
+	foundCorrupt, mutations, errCr := readFaultCorrupt(md, debugLevel)
+	if errCr != nil {
+		return nil, errCr
+	}
+	if foundCorrupt {
+		inj.corrupt = mutations
+		return nil, inj
+	}
+
 	switch len(faultCodes) {
 	case 0:
-		code = rand.RandomFaultCode()
+		if foundDelay {
+			inj.delayOnly = true
+			return nil, inj
+		}
+		inj.code = rand.RandomFaultCode()
 	case 1:
-		code = faultCodes[0]
+		inj.code = faultCodes[0]
 	default:
-		code = rand.RandomSuppliedFaultCode(&faultCodes)
+		inj.code = rand.RandomSuppliedFaultCode(&faultCodes)
+	}
+
+	if debugLevel > 11 {
+		logger.Printf("faultInject counter:%d code:%d delay:%s", counter, uint32(inj.code), inj.delay)
+	}
+
+	return nil, inj
+}
+
+// faultInjectCode returns the fault decision, which is applied by applyFault
+func faultInjectCode(
+	counter uint64, code codes.Code, debugLevel int) (any, error) {
+
+	if debugLevel > 11 {
+		logger.Printf("faultInjectCode counter:%d code:%d", counter, uint32(code))
+	}
+
+	return nil, &injectedFault{
+		counter: counter,
+		code:    code,
+	}
+}
+
+// injectedFault is the decision to fault the request
+// The decision is returned as an error, so every fault is applied in one place,
+// after checking the fault budget
+// connection is the optional "faultconnection" action, instead of the code
+// corrupt is the optional "faultcorrupt" mutations of the response, instead of the code
+// panicValue is the optional "faultpanic" value, which is panicked, instead of the code
+// cancel is the optional "faultcancel", which cancels the handler context after cancelAfter, instead of the code
+// delay is waited before the fault, and delayOnly means the request is delayed, and then handled
+type injectedFault struct {
+	counter     uint64
+	delay       time.Duration
+	delayOnly   bool
+	code        codes.Code
+	trailers    metadata.MD
+	connection  connectionAction
+	corrupt     []mutate.Mutation
+	panicValue  string
+	cancel      bool
+	cancelAfter time.Duration
+}
+
+func (f *injectedFault) Error() string {
+	return "injected fault code:" + f.code.String()
+}
+
+// applyFault returns the fault, unless the fault budget is exhausted, in which
+// case the fault is silently skipped, and the handler is called
+// In dry run mode the fault is logged and counted, and the handler is called
+// The fault code zero (0) returns a zero valued response, with OK
+func applyFault(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	inj *injectedFault,
+	method string,
+	b *budget.Budget,
+	conns Connections,
+	cancelGrace time.Duration,
+	debugLevel int) (any, error) {
+
+	if faultSwitch.DryRun() {
+		d := dryRun.Add(1)
+		if debugLevel > 10 {
+			logger.Printf("dry run fault code:%d delay:%s counter:%d dryRun:%d", uint32(inj.code), inj.delay, inj.counter, d)
+		}
+		return noFaultInject(ctx, req, handler, debugLevel)
+	}
+
+	if b != nil {
+		if reason := b.Allow(callerKey(ctx)); reason != budget.Allowed {
+			budgetExhausted(reason, debugLevel)
+			return noFaultInject(ctx, req, handler, debugLevel)
+		}
+	}
+
+	if inj.delay > 0 {
+		if err := delay(ctx, inj.delay); err != nil {
+			return nil, err
+		}
+	}
+
+	if inj.delayOnly {
+		d := delays.Add(1)
+		if debugLevel > 10 {
+			logger.Printf("applyFault delay:%s counter:%d delays:%d", inj.delay, inj.counter, d)
+		}
+		return noFaultInject(ctx, req, handler, debugLevel)
+	}
+
+	if inj.connection != "" {
+		return applyConnection(ctx, req, handler, inj, conns, debugLevel)
 	}
 
+	if inj.trailers != nil {
+		if err := grpc.SetTrailer(ctx, inj.trailers); err != nil && debugLevel > 10 {
+			logger.Printf("applyFault SetTrailer error:%v", err)
+		}
+	}
+
+	if inj.panicValue != "" {
+		applyPanic(ctx, inj, debugLevel)
+	}
+
+	if inj.cancel {
+		return applyCancel(ctx, req, handler, inj, method, cancelGrace, debugLevel)
+	}
+
+	if inj.corrupt != nil {
+		return applyCorrupt(ctx, req, handler, inj, debugLevel)
+	}
+
+	if inj.code == codes.OK {
+		return applyEmpty(ctx, req, handler, inj, method, debugLevel)
+	}
+
+	f := fault.Add(1)
+	s := success.Load()
+
 	if debugLevel > 10 {
-		logger.Print(logFaultRequest(s, f, code))
+		logger.Print(logFaultRequest(s, f, inj.code))
 	}
 
 	return nil, status.Errorf(
-		code,
+		inj.code,
 		"intercept fault code:%d counter:%d success:%d fault:%d",
-		uint32(code), counter, s, f)
+		uint32(inj.code), inj.counter, s, f)
+}
+
+// applyConnection closes the calling connection, or drains the server
+// A closed connection fails the request, and a drained server handles the request,
+// while the clients are sent a GOAWAY
+func applyConnection(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	inj *injectedFault,
+	conns Connections,
+	debugLevel int) (any, error) {
+
+	if conns == nil {
+		if debugLevel > 10 {
+			logger.Printf("applyConnection action:%s ignored, no Connections configured", inj.connection)
+		}
+		return noFaultInject(ctx, req, handler, debugLevel)
+	}
+
+	c := connections.Add(1)
+
+	if inj.connection == connectionGoaway {
+		if debugLevel > 10 {
+			logger.Printf("applyConnection goaway counter:%d connections:%d", inj.counter, c)
+		}
+		conns.Drain()
+		return noFaultInject(ctx, req, handler, debugLevel)
+	}
+
+	var addr net.Addr
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr
+	}
+
+	closed := conns.CloseConn(addr, inj.connection == connectionReset)
+
+	if debugLevel > 10 {
+		logger.Printf("applyConnection %s peer:%s closed:%t counter:%d connections:%d",
+			inj.connection, peerAddress(ctx), closed, inj.counter, c)
+	}
+
+	f := fault.Add(1)
+
+	return nil, status.Errorf(
+		inj.code,
+		"intercept fault connection:%s counter:%d fault:%d",
+		inj.connection, inj.counter, f)
+}
+
+// applyCorrupt calls the handler, and then corrupts a copy of the response
+// The handler may return a shared message, so the response is cloned before the mutations
+// A handler error, or a response which isn't a protobuf message, is returned unchanged
+func applyCorrupt(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	inj *injectedFault,
+	debugLevel int) (any, error) {
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		if debugLevel > 10 {
+			logger.Printf("applyCorrupt response:%T isn't a protobuf message", resp)
+		}
+		return resp, err
+	}
+
+	msg = proto.Clone(msg)
+	changed := mutate.Apply(msg, inj.corrupt)
+
+	f := fault.Add(1)
+	c := corrupted.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("applyCorrupt mutations:%s changed:%d counter:%d fault:%d corrupted:%d",
+			mutate.String(inj.corrupt), changed, inj.counter, f, c)
+	}
+
+	return msg, nil
 }
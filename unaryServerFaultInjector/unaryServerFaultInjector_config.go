@@ -0,0 +1,136 @@
+package unaryServerFaultInjector
+
+// This .go file holds the configuration types, which are built with, and without, the
+// "nofaultinjection" build tag.  UnaryServerInterceptorConfig is in _config_fault.go, and
+// the stub in _config_nofault.go, so the production binaries don't link faultScenario
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Scope controls which requests share a request counter
+type Scope int32
+
+const (
+	// ScopeGlobal is a single counter for every request to the server ( the default )
+	ScopeGlobal Scope = iota
+	// ScopeMethod is a counter per GRPC method
+	ScopeMethod Scope = 1
+	// ScopePeer is a counter per client peer address
+	ScopePeer Scope = 2
+	// ScopeIdentity is a counter per authenticated ( mTLS ) client identity
+	ScopeIdentity Scope = 3
+	// ScopeSession is a counter per "faultsession" header
+	ScopeSession Scope = 4
+)
+
+// DefaultCancelGrace is the default CancelGrace
+const DefaultCancelGrace = 100 * time.Millisecond
+
+// Connections closes the calling connection, or drains the server, e.g. a faultnet.Server
+// CloseConn closes the connection from the remote address, and returns false if there is none
+// Drain sends a GOAWAY to the clients, and gracefully drains the connections
+type Connections interface {
+	CloseConn(addr net.Addr, reset bool) bool
+	Drain()
+}
+
+// Budget limits the faults, so a misconfigured client can't take down a shared server
+// Once a limit is reached, faults are silently skipped, and the request is handled as normal
+// MaxFaults is the total number of faults
+// FaultsPerSecond and Burst are a token bucket.  Burst zero (0) is the FaultsPerSecond rounded up
+// MaxFaultsPerCaller is the total number of faults per caller, which is the mTLS identity, or the client IP
+// The callers use MaxCounters and CounterTTL, so a caller unused for the CounterTTL, or evicted, is reset,
+// and gets a new MaxFaultsPerCaller.  MaxFaults and FaultsPerSecond limit all the callers
+// Zero (0) is unlimited
+type Budget struct {
+	MaxFaults          uint64
+	FaultsPerSecond    float64
+	Burst              int
+	MaxFaultsPerCaller uint64
+}
+
+// Trust limits who can send fault headers, so anyone who can reach the server can't make it fail
+// All the configured checks must pass
+// Peers is an allowlist of client CIDRs, e.g. netip.MustParsePrefix("10.0.0.0/8")
+// Identities is an allowlist of mTLS identities ( URI SAN, DNS SAN, or CN ), so clients must have a certificate
+// Secret is the HMAC shared secret, so the fault headers must be signed, see "faultsignature"
+// MaxAge is the longest a signature can be valid, and zero (0) is 5 minutes
+// Reject returns PermissionDenied for untrusted fault headers, otherwise the fault headers are ignored
+type Trust struct {
+	Peers      []netip.Prefix
+	Identities []string
+	Secret     []byte
+	MaxAge     time.Duration
+	Reject     bool
+}
+
+// Stats are the server interceptor counters, which are shared by all the server interceptors
+// RampPPM is the most recent "faultramp" fault rate, in parts-per-million ( 10000 = 1% )
+// BudgetTotal, BudgetRate, and BudgetCaller are the faults skipped, because the Budget
+// MaxFaults, FaultsPerSecond, or MaxFaultsPerCaller was reached
+// Disabled is the requests passed through while disabled, and DryRun is the faults not injected in dry run mode
+// Untrusted is the requests with fault headers, which failed the Trust checks
+// Delays is the selected requests which were only delayed, by "faultdelay" without "faultcodes", or a scenario rule delay
+// Connections is the "faultconnection" closes and drains
+// Corrupted is the "faultcorrupt" responses, which are also counted in Faults
+// Empty is the zero valued responses, for the fault code zero (0), which are also counted in Faults
+// Panics is the "faultpanic" panics, which are also counted in Faults
+// Cancels is the "faultcancel" cancelled handler contexts, which are also counted in Faults,
+// and CancelIgnored is the handlers still running after the CancelGrace
+type Stats struct {
+	Requests      uint64
+	Success       uint64
+	Faults        uint64
+	RampPPM       int64
+	BudgetTotal   uint64
+	BudgetRate    uint64
+	BudgetCaller  uint64
+	Disabled      uint64
+	DryRun        uint64
+	Untrusted     uint64
+	Delays        uint64
+	Connections   uint64
+	Corrupted     uint64
+	Empty         uint64
+	Panics        uint64
+	Cancels       uint64
+	CancelIgnored uint64
+}
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeGlobal:
+		return "global"
+	case ScopeMethod:
+		return "method"
+	case ScopePeer:
+		return "peer"
+	case ScopeIdentity:
+		return "identity"
+	case ScopeSession:
+		return "session"
+	default:
+		return "invalid"
+	}
+}
+
+func StringToScope(str string) (scope Scope) {
+	switch strings.ToLower(str) {
+	case "global":
+		scope = ScopeGlobal
+	case "method":
+		scope = ScopeMethod
+	case "peer":
+		scope = ScopePeer
+	case "identity":
+		scope = ScopeIdentity
+	case "session":
+		scope = ScopeSession
+		//default:
+	}
+	return scope
+}
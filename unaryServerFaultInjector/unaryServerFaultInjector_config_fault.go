@@ -0,0 +1,45 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/faultScenario"
+)
+
+// UnaryServerInterceptorConfig is the optional server configuration
+// Scope is the default counter scope, which the client can override with the "faultscope" header
+// MaxCounters and CounterTTL bound the memory used by the scoped counters.
+// Zero (0) uses the defaults of 10000 counters, and 10 minutes
+// Scenario is optional, and the first matching rule decides if the request is faulted.
+// Requests which don't match any rule use the fault headers
+// ScenarioWatcher is optional, and reloads the scenario when the file changes.
+// The ScenarioWatcher is used instead of the Scenario
+// Budget is optional, and limits the number of faults
+// Trust is optional, and limits who can send fault headers
+// Service is optional, and is the name this server matches in the "faulttarget" header,
+// in addition to the gRPC service name and full method
+// Connections is optional, and closes or drains the connections for the "faultconnection" header
+// CancelGrace is how long a handler has to return, after the "faultcancel" cancel, before it is
+// reported as ignoring the cancellation.  Zero (0) is DefaultCancelGrace
+type UnaryServerInterceptorConfig struct {
+	Scope           Scope
+	MaxCounters     int
+	CounterTTL      time.Duration
+	Scenario        *faultScenario.Scenario
+	ScenarioWatcher *faultScenario.Watcher
+	Budget          Budget
+	Trust           Trust
+	Service         string
+	Connections     Connections
+	CancelGrace     time.Duration
+}
+
+// scenario returns the active scenario, or nil
+func (c *UnaryServerInterceptorConfig) scenario() *faultScenario.Scenario {
+	if c.ScenarioWatcher != nil {
+		return c.ScenarioWatcher.Scenario()
+	}
+	return c.Scenario
+}
@@ -0,0 +1,22 @@
+//go:build nofaultinjection
+
+package unaryServerFaultInjector
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// The config has no Scenario, or ScenarioWatcher, so the faultScenario package isn't linked
+
+import (
+	"time"
+)
+
+// UnaryServerInterceptorConfig is the optional server configuration, which is ignored
+type UnaryServerInterceptorConfig struct {
+	Scope       Scope
+	MaxCounters int
+	CounterTTL  time.Duration
+	Budget      Budget
+	Trust       Trust
+	Service     string
+	Connections Connections
+	CancelGrace time.Duration
+}
@@ -0,0 +1,78 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type controlTest struct {
+	name      string
+	enabled   bool
+	dryRun    bool
+	expectErr bool
+	handled   bool
+	disabled  uint64
+	dryRuns   uint64
+}
+
+// go test -run TestControl -v
+func TestControl(t *testing.T) {
+	tests := []controlTest{
+		{name: "enabled", enabled: true, expectErr: true, handled: false},
+		{name: "disabled", enabled: false, expectErr: false, handled: true, disabled: 1},
+		{name: "dry run", enabled: true, dryRun: true, expectErr: false, handled: true, dryRuns: 1},
+	}
+
+	defer func() {
+		Enable()
+		SetDryRun(false)
+	}()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.enabled {
+				Enable()
+			} else {
+				Disable()
+			}
+			SetDryRun(tt.dryRun)
+
+			interceptor := UnaryServerFaultInjector(0)
+
+			var handled bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled = true
+				return req, nil
+			}
+
+			before := GetStats()
+
+			ctx := metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14"))
+
+			_, err := interceptor(ctx, "req", info, handler)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err)
+			}
+			if handled != tt.handled {
+				t.Errorf("test: %s, handled:%t != tt.handled:%t", tt.name, handled, tt.handled)
+			}
+
+			after := GetStats()
+			if d := after.Disabled - before.Disabled; d != tt.disabled {
+				t.Errorf("test: %s, disabled:%d != tt.disabled:%d", tt.name, d, tt.disabled)
+			}
+			if d := after.DryRun - before.DryRun; d != tt.dryRuns {
+				t.Errorf("test: %s, dryRun:%d != tt.dryRuns:%d", tt.name, d, tt.dryRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	errMethodName = errors.New("invalid method name")
+	errNotService = errors.New("not a service")
+	errNotMethod  = errors.New("method not found")
+)
+
+// applyEmpty returns a zero valued response, of the method response type, with OK,
+// for the fault code zero (0), so the callers which assume a non-empty response can be tested
+// The response type is found from the method descriptor, in the protobuf registry, without
+// calling the handler.  If the method isn't registered, the handler is called, and a new
+// zero valued message, of the same type as the handler response, is returned
+func applyEmpty(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	inj *injectedFault,
+	method string,
+	debugLevel int) (any, error) {
+
+	f := fault.Add(1)
+	e := empty.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("applyEmpty method:%s counter:%d fault:%d empty:%d", method, inj.counter, f, e)
+	}
+
+	resp, err := emptyResponse(method)
+	if err == nil {
+		return resp, nil
+	}
+
+	if debugLevel > 10 {
+		logger.Printf("applyEmpty method:%s error:%v, calling the handler", method, err)
+	}
+
+	r, err := handler(ctx, req)
+	if err != nil {
+		return r, err
+	}
+
+	if msg, ok := r.(proto.Message); ok {
+		return msg.ProtoReflect().Type().New().Interface(), nil
+	}
+
+	return r, nil
+}
+
+// emptyResponse returns a new zero valued response message for the method
+// e.g. "/grpc.examples.echo.Echo/UnaryEcho" returns an empty *pb.EchoResponse
+func emptyResponse(method string) (proto.Message, error) {
+
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		return nil, errMethodName
+	}
+
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, err
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errNotService
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, errNotMethod
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	return mt.New().Interface(), nil
+}
@@ -0,0 +1,86 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/examples/features/proto/echo"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type emptyTest struct {
+	name    string
+	method  string
+	resp    any
+	expect  any
+	handled bool
+}
+
+// go test -run TestEmpty -v
+func TestEmpty(t *testing.T) {
+	tests := []emptyTest{
+		{
+			name:    "registered method, handler not called",
+			method:  "/grpc.examples.echo.Echo/UnaryEcho",
+			resp:    &echo.EchoResponse{Message: "message"},
+			expect:  &echo.EchoResponse{},
+			handled: false,
+		},
+		{
+			name:    "unregistered method, empty handler response type",
+			method:  "/other.Service/Method",
+			resp:    &descriptorpb.FieldDescriptorProto{Name: proto.String("message")},
+			expect:  &descriptorpb.FieldDescriptorProto{},
+			handled: true,
+		},
+		{
+			name:    "unregistered method, not a protobuf message",
+			method:  "/other.Service/Method",
+			resp:    "message",
+			expect:  "message",
+			handled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var handled bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled = true
+				return tt.resp, nil
+			}
+
+			interceptor := UnaryServerFaultInjector(0)
+
+			before := GetStats().Empty
+
+			ctx := metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "0"))
+			resp, err := interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if err != nil {
+				t.Fatalf("test: %s, err:%v", tt.name, err)
+			}
+
+			if m, ok := tt.expect.(proto.Message); ok {
+				r, ok := resp.(proto.Message)
+				if !ok || !proto.Equal(r, m) {
+					t.Errorf("test: %s, resp:%v != tt.expect:%v", tt.name, resp, tt.expect)
+				}
+			} else if resp != tt.expect {
+				t.Errorf("test: %s, resp:%v != tt.expect:%v", tt.name, resp, tt.expect)
+			}
+			if handled != tt.handled {
+				t.Errorf("test: %s, handled:%t != tt.handled:%t", tt.name, handled, tt.handled)
+			}
+			if e := GetStats().Empty - before; e != 1 {
+				t.Errorf("test: %s, empty:%d != 1", tt.name, e)
+			}
+		})
+	}
+}
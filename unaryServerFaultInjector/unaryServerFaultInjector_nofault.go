@@ -0,0 +1,61 @@
+//go:build nofaultinjection
+
+package unaryServerFaultInjector
+
+// This .go file is built with the "nofaultinjection" build tag, for production binaries
+// The interceptors pass every request straight to the handler, and none of the fault
+// injection code is compiled in, so the headers and scenario files have no effect
+//
+// go build -tags nofaultinjection
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerFaultInjector passes every request to the handler
+func UnaryServerFaultInjector(debugLevel int) grpc.UnaryServerInterceptor {
+	return passThrough
+}
+
+// UnaryServerFaultInjectorWithConfig passes every request to the handler, and the config is ignored
+func UnaryServerFaultInjectorWithConfig(config UnaryServerInterceptorConfig, debugLevel int) grpc.UnaryServerInterceptor {
+	return passThrough
+}
+
+func passThrough(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(ctx, req)
+}
+
+// Enable does nothing, fault injection is not compiled in
+func Enable() {}
+
+// Disable does nothing, fault injection is not compiled in
+func Disable() {}
+
+// Enabled is always false
+func Enabled() bool {
+	return false
+}
+
+// SetDryRun does nothing, fault injection is not compiled in
+func SetDryRun(dryRun bool) {}
+
+// DryRun is always false
+func DryRun() bool {
+	return false
+}
+
+// HandleSignals returns immediately, so the signals keep their default behavior
+func HandleSignals(ctx context.Context) {}
+
+// PropagateFaultHeaders returns the ctx unchanged, so no fault headers are propagated
+func PropagateFaultHeaders(ctx context.Context) context.Context {
+	return ctx
+}
+
+// GetStats is always zero
+func GetStats() Stats {
+	return Stats{}
+}
@@ -0,0 +1,41 @@
+//go:build nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// go test -tags nofaultinjection -run TestNoFault -v
+func TestNoFault(t *testing.T) {
+
+	interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{}, 11)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("faultmodulus", "1", "faultcodes", "14"))
+
+	resp, err := interceptor(ctx, "req", info, handler)
+	if err != nil {
+		t.Fatalf("unexpected error:%v", err)
+	}
+	if resp != "req" {
+		t.Errorf("resp:%v != req", resp)
+	}
+
+	Enable()
+	if Enabled() {
+		t.Error("Enabled() is true")
+	}
+	if s := GetStats(); s != (Stats{}) {
+		t.Errorf("stats:%+v are not zero", s)
+	}
+}
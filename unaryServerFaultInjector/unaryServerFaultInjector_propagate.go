@@ -0,0 +1,131 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faulttargetHeader = "faulttarget"
+	faulthopsHeader   = signature.HopsHeader
+)
+
+// propagateKey is the context key of the fault headers to propagate downstream
+type propagateKey struct{}
+
+// readFaultHops reads the "faulthops", including validation
+// hops needs to be a integer between 0-32
+// the fault fires when the hops is zero (0), and each PropagateFaultHeaders decrements the hops
+func readFaultHops(md *metadata.MD, debugLevel int) (found bool, faultHops int, err error) {
+
+	var faultHopsValue []string
+
+	if faultHopsValue, found = (*md)[faulthopsHeader]; found {
+
+		fh, err := strconv.ParseInt(faultHopsValue[0], 0, 64)
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultHops ParseInt error")
+		}
+
+		var errV error
+		faultHops, errV = validate.ValidateHops(fh)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultHops ValidateHops error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultHops faultHops:%d", faultHops)
+		}
+
+		return found, faultHops, nil
+	}
+
+	// faulthopsHeader does not exist
+	return found, 0, nil
+}
+
+// forThisHop returns true if the fault headers are for this service
+// "faulttarget" must match the config Service, the gRPC service, or the full method
+// e.g. "C", "grpc.examples.echo.Echo", or "/grpc.examples.echo.Echo/UnaryEcho"
+// "faulthops" must be zero (0)
+func forThisHop(md *metadata.MD, fullMethod string, service string, hops int) bool {
+
+	if hops > 0 {
+		return false
+	}
+
+	target, found := (*md)[faulttargetHeader]
+	if !found {
+		return true
+	}
+
+	return target[0] == service ||
+		target[0] == serviceName(fullMethod) ||
+		target[0] == fullMethod
+}
+
+// serviceName returns the gRPC service of the full method
+// e.g. "/grpc.examples.echo.Echo/UnaryEcho" is "grpc.examples.echo.Echo"
+func serviceName(fullMethod string) string {
+	s := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// propagateMD returns the fault headers to send downstream, with the hops decremented
+// nil is returned if there are no fault headers, or the hops have run out
+func propagateMD(md metadata.MD, foundHops bool, hops int) metadata.MD {
+
+	var out metadata.MD
+	for k, v := range md {
+		if signature.IsFaultHeader(k) {
+			if out == nil {
+				out = metadata.MD{}
+			}
+			out[k] = v
+		}
+	}
+
+	if out == nil || !foundHops {
+		return out
+	}
+
+	if hops == 0 {
+		return nil
+	}
+	out.Set(faulthopsHeader, strconv.Itoa(hops-1))
+
+	return out
+}
+
+// PropagateFaultHeaders copies the fault headers of the incoming request to the outgoing
+// context, so a fault requested upstream can fire at a downstream service
+// Use the returned context in the handler for the downstream calls
+// e.g. A->B->C, where A sends "faulttarget: C", or "faulthops: 2"
+// Only fault headers which passed the Trust checks are propagated, and the
+// ctx must be from a handler behind UnaryServerFaultInjectorWithConfig
+func PropagateFaultHeaders(ctx context.Context) context.Context {
+
+	md, ok := ctx.Value(propagateKey{}).(metadata.MD)
+	if !ok {
+		return ctx
+	}
+
+	outMD, _ := metadata.FromOutgoingContext(ctx)
+
+	return metadata.NewOutgoingContext(ctx, metadata.Join(outMD, md))
+}
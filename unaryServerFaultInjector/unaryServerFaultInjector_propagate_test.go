@@ -0,0 +1,108 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type propagateTest struct {
+	name    string
+	service string
+	md      metadata.MD
+	code    codes.Code
+	hops    []string
+	target  []string
+}
+
+// go test -run TestPropagate -v
+func TestPropagate(t *testing.T) {
+	tests := []propagateTest{
+		{
+			name: "no fault headers, nothing propagated",
+			md:   metadata.Pairs("x-other", "1"),
+			code: codes.OK,
+		},
+		{
+			name: "no target or hops, fault here and propagated",
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14"),
+			code: codes.Unavailable,
+		},
+		{
+			name: "hops 2, propagated with hops 1",
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14", faulthopsHeader, "2"),
+			code: codes.OK,
+			hops: []string{"1"},
+		},
+		{
+			name: "hops 0, fault here and not propagated",
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14", faulthopsHeader, "0"),
+			code: codes.Unavailable,
+		},
+		{
+			name:   "target another service, propagated",
+			md:     metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14", faulttargetHeader, "C"),
+			code:   codes.OK,
+			target: []string{"C"},
+		},
+		{
+			name:    "target config service, fault here",
+			service: "C",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14", faulttargetHeader, "C"),
+			code:    codes.Unavailable,
+		},
+		{
+			name:   "target gRPC service, fault here",
+			md:     metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14", faulttargetHeader, "grpc.examples.echo.Echo"),
+			code:   codes.Unavailable,
+			target: []string{"grpc.examples.echo.Echo"},
+		},
+		{
+			name: "invalid hops 33",
+			md:   metadata.Pairs(faultmodulusHeader, "1", faulthopsHeader, "33"),
+			code: codes.InvalidArgument,
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{Service: tt.service}, 0)
+
+			var out metadata.MD
+			handler := func(ctx context.Context, req any) (any, error) {
+				out, _ = metadata.FromOutgoingContext(PropagateFaultHeaders(ctx))
+				return req, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			_, err := interceptor(ctx, "req", info, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("test: %s, code:%s != tt.code:%s", tt.name, code, tt.code)
+			}
+			if err != nil {
+				return
+			}
+
+			if out.Get("x-other") != nil {
+				t.Errorf("test: %s, non fault header propagated", tt.name)
+			}
+			if got := out.Get(faulthopsHeader); !slices.Equal(got, tt.hops) {
+				t.Errorf("test: %s, hops:%v != tt.hops:%v", tt.name, got, tt.hops)
+			}
+			if got := out.Get(faulttargetHeader); !slices.Equal(got, tt.target) {
+				t.Errorf("test: %s, target:%v != tt.target:%v", tt.name, got, tt.target)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultcancelHeader = "faultcancel"
+
+	// faultcancelPromptTrailer is "true" if the handler returned within the CancelGrace
+	faultcancelPromptTrailer = "faultcancel-prompt"
+
+	// faultcancelNotCancelled is the "faultcancel-prompt" value, if the handler returned before the cancel
+	faultcancelNotCancelled = "not-cancelled"
+)
+
+// readFaultCancel reads the optional "faultcancel", including validation
+// the value is a Go duration between 0 and 5 minutes, after which the handler context is cancelled
+// The selected request runs the handler, and cancels the handler context, instead of returning a fault code
+// e.g. faultcancel = 0 ( cancel immediately )
+// e.g. faultcancel = 50ms ( cancel 50ms after the handler starts )
+func readFaultCancel(md *metadata.MD, debugLevel int) (found bool, after time.Duration, err error) {
+
+	var faultCancelValue []string
+
+	if faultCancelValue, found = (*md)[faultcancelHeader]; found {
+
+		fc, err := time.ParseDuration(faultCancelValue[0])
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultCancel ParseDuration error")
+		}
+
+		var errV error
+		after, errV = validate.ValidateDelay(fc)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultCancel ValidateDelay error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultCancel after:%s", after)
+		}
+
+		return found, after, nil
+	}
+
+	// faultcancelHeader does not exist
+	return found, 0, nil
+}
+
+// applyCancel runs the handler with a context, which is cancelled after the delay, and reports
+// whether the handler returned within the grace after the cancel
+// A handler which is still running after the grace is logged, and counted as CancelIgnored,
+// even if it never returns, so the handlers which ignore the context, and leak, are found
+// The "faultcancel-prompt" trailer is "true" or "false", comparing the handler return time with
+// the cancel time plus the grace, or "not-cancelled" if the handler returned before the cancel
+func applyCancel(
+	ctx context.Context,
+	req any,
+	handler grpc.UnaryHandler,
+	inj *injectedFault,
+	method string,
+	grace time.Duration,
+	debugLevel int) (any, error) {
+
+	if grace <= 0 {
+		grace = DefaultCancelGrace
+	}
+
+	f := fault.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("applyCancel method:%s after:%s counter:%d fault:%d",
+			method, inj.cancelAfter, inj.counter, f)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// mu protects cancelledAt, returned, and ignored, so the handler is either counted
+	// as ignored by the grace timer, or returned within the grace, but not both
+	var (
+		mu          sync.Mutex
+		cancelledAt time.Time
+		returned    bool
+		ignored     bool
+		graceTimer  *time.Timer
+	)
+
+	cancelTimer := time.AfterFunc(inj.cancelAfter, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if returned {
+			return
+		}
+		cancelledAt = time.Now()
+		cancel()
+		// only the cancels which happen are counted, not a handler which returned first
+		c := cancels.Add(1)
+		if debugLevel > 10 {
+			logger.Printf("applyCancel method:%s cancelled cancels:%d", method, c)
+		}
+		graceTimer = time.AfterFunc(grace, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if returned {
+				return
+			}
+			ignored = true
+			i := cancelIgnored.Add(1)
+			logger.Printf("applyCancel method:%s handler still running %s after the cancel, cancelIgnored:%d",
+				method, grace, i)
+		})
+	})
+
+	resp, err := handler(cctx, req)
+
+	mu.Lock()
+	returnedAt := time.Now()
+	returned = true
+	cancelTimer.Stop()
+	if graceTimer != nil {
+		graceTimer.Stop()
+	}
+
+	var prompt string
+	switch {
+	case cancelledAt.IsZero():
+		prompt = faultcancelNotCancelled
+	case ignored || returnedAt.After(cancelledAt.Add(grace)):
+		if !ignored {
+			ignored = true
+			cancelIgnored.Add(1)
+		}
+		prompt = strconv.FormatBool(false)
+	default:
+		prompt = strconv.FormatBool(true)
+	}
+	mu.Unlock()
+
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(faultcancelPromptTrailer, prompt)); err != nil && debugLevel > 10 {
+		logger.Printf("applyCancel SetTrailer error:%v", err)
+	}
+
+	if debugLevel > 10 {
+		logger.Printf("applyCancel method:%s prompt:%s error:%v", method, prompt, err)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,187 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type readFaultCancelTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	after     time.Duration
+}
+
+// go test -run TestReadFaultCancel -v
+func TestReadFaultCancel(t *testing.T) {
+	tests := []readFaultCancelTest{
+		{
+			name:  "valid no fault cancel header",
+			md:    metadata.Pairs("anotherHeader", "doesn_t_matter"),
+			found: false,
+		},
+		{
+			name:  "valid, immediately",
+			md:    metadata.Pairs(faultcancelHeader, "0"),
+			found: true,
+			after: 0,
+		},
+		{
+			name:  "valid, 50ms",
+			md:    metadata.Pairs(faultcancelHeader, "50ms"),
+			found: true,
+			after: 50 * time.Millisecond,
+		},
+		{
+			name:      "invalid, blah",
+			md:        metadata.Pairs(faultcancelHeader, "blah"),
+			expectErr: true,
+			found:     true,
+		},
+		{
+			name:      "invalid, 1h",
+			md:        metadata.Pairs(faultcancelHeader, "1h"),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, after, err := readFaultCancel(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s, found:%t != tt.found:%t", tt.name, found, tt.found)
+			}
+			if after != tt.after {
+				t.Errorf("test: %s, after:%s != tt.after:%s", tt.name, after, tt.after)
+			}
+		})
+	}
+}
+
+// trailerStream records the trailer, in place of the server transport stream
+type trailerStream struct {
+	trailer metadata.MD
+}
+
+func (s *trailerStream) Method() string                  { return "/grpc.examples.echo.Echo/UnaryEcho" }
+func (s *trailerStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *trailerStream) SendHeader(md metadata.MD) error { return nil }
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+type cancelTest struct {
+	name    string
+	md      metadata.MD
+	ignore  time.Duration
+	code    codes.Code
+	prompt  string
+	cancels uint64
+	ignored uint64
+}
+
+// go test -run TestCancel -v
+func TestCancel(t *testing.T) {
+	tests := []cancelTest{
+		{
+			name:    "cancel immediately, handler honours the context",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcancelHeader, "0"),
+			code:    codes.Canceled,
+			prompt:  "true",
+			cancels: 1,
+		},
+		{
+			name:    "cancel after 10ms, handler honours the context",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcancelHeader, "10ms"),
+			code:    codes.Canceled,
+			prompt:  "true",
+			cancels: 1,
+		},
+		{
+			name:    "handler ignores the context",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcancelHeader, "0"),
+			ignore:  100 * time.Millisecond,
+			code:    codes.OK,
+			prompt:  "false",
+			cancels: 1,
+			ignored: 1,
+		},
+		{
+			name:    "handler returns before the cancel",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcancelHeader, "1s"),
+			ignore:  time.Millisecond,
+			code:    codes.OK,
+			prompt:  faultcancelNotCancelled,
+			cancels: 0,
+		},
+		{
+			name: "not selected, no fault mode",
+			md:   metadata.Pairs(faultcancelHeader, "0"),
+			code: codes.OK,
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			handler := func(ctx context.Context, req any) (any, error) {
+				if tt.ignore > 0 {
+					time.Sleep(tt.ignore)
+					return req, nil
+				}
+				select {
+				case <-ctx.Done():
+					return nil, status.FromContextError(ctx.Err()).Err()
+				case <-time.After(time.Second):
+					return req, nil
+				}
+			}
+
+			interceptor := UnaryServerFaultInjectorWithConfig(
+				UnaryServerInterceptorConfig{CancelGrace: 20 * time.Millisecond}, 0)
+
+			before := GetStats()
+
+			stream := &trailerStream{}
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+			_, err := interceptor(ctx, "req", info, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("test: %s, code:%s != tt.code:%s", tt.name, code, tt.code)
+			}
+
+			var prompt string
+			if v := stream.trailer.Get(faultcancelPromptTrailer); len(v) > 0 {
+				prompt = v[0]
+			}
+			if prompt != tt.prompt {
+				t.Errorf("test: %s, prompt:%q != tt.prompt:%q", tt.name, prompt, tt.prompt)
+			}
+
+			after := GetStats()
+			if c := after.Cancels - before.Cancels; c != tt.cancels {
+				t.Errorf("test: %s, cancels:%d != tt.cancels:%d", tt.name, c, tt.cancels)
+			}
+			if i := after.CancelIgnored - before.CancelIgnored; i != tt.ignored {
+				t.Errorf("test: %s, ignored:%d != tt.ignored:%d", tt.name, i, tt.ignored)
+			}
+		})
+	}
+}
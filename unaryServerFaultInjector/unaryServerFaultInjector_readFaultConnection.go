@@ -0,0 +1,57 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	faultconnectionHeader = "faultconnection"
+)
+
+// connectionAction is the "faultconnection" action
+type connectionAction string
+
+const (
+	// connectionClose closes the calling connection, with a FIN
+	connectionClose connectionAction = "close"
+	// connectionReset closes the calling connection, with a TCP RST
+	connectionReset connectionAction = "reset"
+	// connectionGoaway drains the server, so the clients get a GOAWAY
+	connectionGoaway connectionAction = "goaway"
+)
+
+// readFaultConnection reads the optional "faultconnection", including validation
+// the action is only applied to the requests selected by the mode, e.g. "faultmodulus"
+// e.g. faultconnection = close ( close the calling connection )
+// e.g. faultconnection = reset ( reset the calling connection )
+// e.g. faultconnection = goaway ( GOAWAY and gracefully drain the connections )
+func readFaultConnection(md *metadata.MD, debugLevel int) (found bool, action connectionAction, err error) {
+
+	var faultConnectionValue []string
+
+	if faultConnectionValue, found = (*md)[faultconnectionHeader]; found {
+
+		action = connectionAction(strings.ToLower(faultConnectionValue[0]))
+		switch action {
+		case connectionClose, connectionReset, connectionGoaway:
+		default:
+			return found, "", status.Error(codes.InvalidArgument,
+				"readFaultConnection invalid action, must be close, reset, or goaway")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultConnection action:%s", action)
+		}
+
+		return found, action, nil
+	}
+
+	// faultconnectionHeader does not exist
+	return found, "", nil
+}
@@ -0,0 +1,156 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type readFaultConnectionTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	action    connectionAction
+}
+
+// go test -run TestReadFaultConnection -v
+func TestReadFaultConnection(t *testing.T) {
+	tests := []readFaultConnectionTest{
+		{
+			name:  "valid no fault connection header",
+			md:    metadata.Pairs("anotherHeader", "doesn_t_matter"),
+			found: false,
+		},
+		{
+			name:   "valid, close",
+			md:     metadata.Pairs(faultconnectionHeader, "close"),
+			found:  true,
+			action: connectionClose,
+		},
+		{
+			name:   "valid, RESET",
+			md:     metadata.Pairs(faultconnectionHeader, "RESET"),
+			found:  true,
+			action: connectionReset,
+		},
+		{
+			name:   "valid, goaway",
+			md:     metadata.Pairs(faultconnectionHeader, "goaway"),
+			found:  true,
+			action: connectionGoaway,
+		},
+		{
+			name:      "invalid, blah",
+			md:        metadata.Pairs(faultconnectionHeader, "blah"),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, action, err := readFaultConnection(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s, found:%t != tt.found:%t", tt.name, found, tt.found)
+			}
+			if action != tt.action {
+				t.Errorf("test: %s, action:%s != tt.action:%s", tt.name, action, tt.action)
+			}
+		})
+	}
+}
+
+// fakeConnections records the connection actions
+type fakeConnections struct {
+	closed []string
+	resets int
+	drains int
+}
+
+func (f *fakeConnections) CloseConn(addr net.Addr, reset bool) bool {
+	f.closed = append(f.closed, addr.String())
+	if reset {
+		f.resets++
+	}
+	return true
+}
+
+func (f *fakeConnections) Drain() {
+	f.drains++
+}
+
+type connectionTest struct {
+	name   string
+	action string
+	mode   []string
+	loops  int
+	nilCfg bool
+	code   codes.Code
+	closed int
+	resets int
+	drains int
+}
+
+var modulus1 = []string{faultmodulusHeader, "1"}
+
+// go test -run TestConnection -v
+func TestConnection(t *testing.T) {
+	tests := []connectionTest{
+		{name: "close", action: "close", mode: modulus1, loops: 1, code: codes.Unavailable, closed: 1},
+		{name: "reset", action: "reset", mode: modulus1, loops: 1, code: codes.Unavailable, closed: 1, resets: 1},
+		{name: "goaway, request handled", action: "goaway", mode: modulus1, loops: 1, code: codes.OK, drains: 1},
+		{name: "no Connections, ignored", action: "close", mode: modulus1, loops: 1, nilCfg: true, code: codes.OK},
+		{name: "not selected, no fault mode", action: "close", loops: 1, code: codes.OK},
+		{name: "modulus 2, every second request", action: "close", mode: []string{faultmodulusHeader, "2"}, loops: 4, code: codes.Unavailable, closed: 2},
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			conns := &fakeConnections{}
+			conf := UnaryServerInterceptorConfig{Connections: conns}
+			if tt.nilCfg {
+				conf.Connections = nil
+			}
+			interceptor := UnaryServerFaultInjectorWithConfig(conf, 0)
+
+			md := metadata.Pairs(append([]string{faultconnectionHeader, tt.action}, tt.mode...)...)
+
+			for i := 1; i <= tt.loops; i++ {
+				ctx := metadata.NewIncomingContext(context.Background(), md)
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: netAddr(t, "192.0.2.1:1000")})
+
+				_, err := interceptor(ctx, "req", info, handler)
+				if code := status.Code(err); code != tt.code && code != codes.OK {
+					t.Errorf("test: %s, i:%d code:%s != tt.code:%s", tt.name, i, code, tt.code)
+				}
+			}
+			if len(conns.closed) != tt.closed {
+				t.Errorf("test: %s, closed:%d != tt.closed:%d", tt.name, len(conns.closed), tt.closed)
+			}
+			if conns.resets != tt.resets {
+				t.Errorf("test: %s, resets:%d != tt.resets:%d", tt.name, conns.resets, tt.resets)
+			}
+			if conns.drains != tt.drains {
+				t.Errorf("test: %s, drains:%d != tt.drains:%d", tt.name, conns.drains, tt.drains)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/mutate"
+)
+
+const (
+	faultcorruptHeader = "faultcorrupt"
+)
+
+// readFaultCorrupt reads the optional "faultcorrupt", including validation
+// The response of the selected request is corrupted, instead of returning a fault code
+// e.g. faultcorrupt = zero ( zero all the numbers and bools )
+// e.g. faultcorrupt = truncate:message ( truncate the "message" field )
+// e.g. faultcorrupt = enum,clear:id|name ( undefined enums, and clear "id" and "name" )
+// e.g. faultcorrupt = unknown:100,strip:7 ( append unknown field 100, and strip field 7 )
+func readFaultCorrupt(md *metadata.MD, debugLevel int) (found bool, mutations []mutate.Mutation, err error) {
+
+	var faultCorruptValue []string
+
+	if faultCorruptValue, found = (*md)[faultcorruptHeader]; found {
+
+		mutations, err = mutate.Parse(faultCorruptValue[0])
+		if err != nil {
+			return found, nil, status.Error(codes.InvalidArgument,
+				"readFaultCorrupt invalid, must be operations with optional fields, e.g. zero,truncate:message")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultCorrupt mutations:%s", mutate.String(mutations))
+		}
+
+		return found, mutations, nil
+	}
+
+	// faultcorruptHeader does not exist
+	return found, nil, nil
+}
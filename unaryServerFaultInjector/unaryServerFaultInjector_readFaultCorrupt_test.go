@@ -0,0 +1,169 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type readFaultCorruptTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	mutations int
+}
+
+// go test -run TestReadFaultCorrupt -v
+func TestReadFaultCorrupt(t *testing.T) {
+	tests := []readFaultCorruptTest{
+		{
+			name:  "valid no fault corrupt header",
+			md:    metadata.Pairs("anotherHeader", "doesn_t_matter"),
+			found: false,
+		},
+		{
+			name:      "valid, zero",
+			md:        metadata.Pairs(faultcorruptHeader, "zero"),
+			found:     true,
+			mutations: 1,
+		},
+		{
+			name:      "valid, truncate and clear fields",
+			md:        metadata.Pairs(faultcorruptHeader, "truncate:message,clear:1|name"),
+			found:     true,
+			mutations: 2,
+		},
+		{
+			name:      "invalid, blah",
+			md:        metadata.Pairs(faultcorruptHeader, "blah"),
+			expectErr: true,
+			found:     true,
+		},
+		{
+			name:      "invalid, empty",
+			md:        metadata.Pairs(faultcorruptHeader, ""),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, mutations, err := readFaultCorrupt(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s, found:%t != tt.found:%t", tt.name, found, tt.found)
+			}
+			if len(mutations) != tt.mutations {
+				t.Errorf("test: %s, mutations:%v != tt.mutations:%d", tt.name, mutations, tt.mutations)
+			}
+		})
+	}
+}
+
+type corruptTest struct {
+	name    string
+	md      metadata.MD
+	resp    any
+	code    codes.Code
+	expect  string
+	corrupt bool
+}
+
+// go test -run TestCorrupt -v
+func TestCorrupt(t *testing.T) {
+	tests := []corruptTest{
+		{
+			name:    "truncate",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcorruptHeader, "truncate"),
+			resp:    &descriptorpb.FieldDescriptorProto{Name: proto.String("message")},
+			expect:  "mes",
+			corrupt: true,
+		},
+		{
+			name:    "clear by name",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcorruptHeader, "clear:name"),
+			resp:    &descriptorpb.FieldDescriptorProto{Name: proto.String("message")},
+			expect:  "",
+			corrupt: true,
+		},
+		{
+			name:    "strip by number",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultcorruptHeader, "unknown:100,strip:1"),
+			resp:    &descriptorpb.FieldDescriptorProto{Name: proto.String("message")},
+			expect:  "",
+			corrupt: true,
+		},
+		{
+			name:   "not selected, no fault mode",
+			md:     metadata.Pairs(faultcorruptHeader, "truncate"),
+			resp:   &descriptorpb.FieldDescriptorProto{Name: proto.String("message")},
+			expect: "message",
+		},
+		{
+			name:   "not a protobuf message",
+			md:     metadata.Pairs(faultmodulusHeader, "1", faultcorruptHeader, "truncate"),
+			resp:   "message",
+			expect: "message",
+		},
+		{
+			name: "invalid",
+			md:   metadata.Pairs(faultmodulusHeader, "1", faultcorruptHeader, "blah"),
+			resp: "message",
+			code: codes.InvalidArgument,
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			handler := func(ctx context.Context, req any) (any, error) {
+				return tt.resp, nil
+			}
+
+			interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{Scope: ScopeMethod}, 0)
+
+			before := GetStats().Corrupted
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			resp, err := interceptor(ctx, "req", info, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("test: %s, code:%s != tt.code:%s", tt.name, code, tt.code)
+			}
+			if err != nil {
+				return
+			}
+
+			var got string
+			switch r := resp.(type) {
+			case *descriptorpb.FieldDescriptorProto:
+				got = r.GetName()
+				if tt.resp.(*descriptorpb.FieldDescriptorProto).GetName() != "message" {
+					t.Errorf("test: %s, the handler response was changed, not a copy", tt.name)
+				}
+			case string:
+				got = r
+			}
+			if got != tt.expect {
+				t.Errorf("test: %s, got:%q != tt.expect:%q", tt.name, got, tt.expect)
+			}
+
+			if corrupted := GetStats().Corrupted - before; (corrupted == 1) != tt.corrupt {
+				t.Errorf("test: %s, corrupted:%d != tt.corrupt:%t", tt.name, corrupted, tt.corrupt)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultdelayHeader = "faultdelay"
+)
+
+// readFaultDelay reads the optional "faultdelay", including validation
+// the delay is a Go duration between 0 and 5 minutes
+// the delay only applies to the requests selected by the mode, e.g. "faultmodulus", after the
+// dry run and budget checks, and before the fault.  Without "faultcodes", or another action,
+// the selected requests are only delayed, and then handled
+// e.g. faultdelay = 100ms
+// e.g. faultdelay = 2s
+func readFaultDelay(md *metadata.MD, debugLevel int) (found bool, faultDelay time.Duration, err error) {
+
+	var faultDelayValue []string
+
+	if faultDelayValue, found = (*md)[faultdelayHeader]; found {
+
+		fd, err := time.ParseDuration(faultDelayValue[0])
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultDelay ParseDuration error")
+		}
+
+		var errV error
+		faultDelay, errV = validate.ValidateDelay(fd)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultDelay ValidateDelay error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultDelay faultDelay:%s", faultDelay)
+		}
+
+		return found, faultDelay, nil
+	}
+
+	// faultdelayHeader does not exist
+	return found, 0, nil
+}
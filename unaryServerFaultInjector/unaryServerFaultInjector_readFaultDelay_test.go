@@ -0,0 +1,240 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type readFaultDelayTest struct {
+	name          string
+	md            metadata.MD
+	expectErr     bool
+	found         bool
+	validateDelay bool
+	faultDelay    time.Duration
+}
+
+// go test -run TestReadFaultDelay -v
+func TestReadFaultDelay(t *testing.T) {
+	tests := []readFaultDelayTest{
+		{
+			name: "valid no fault delay header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr:     false,
+			found:         false,
+			validateDelay: false,
+			faultDelay:    0,
+		},
+		{
+			name: "valid, 0s delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "0s",
+			),
+			expectErr:     false,
+			found:         true,
+			validateDelay: true,
+			faultDelay:    0,
+		},
+		{
+			name: "valid, 100ms delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "100ms",
+			),
+			expectErr:     false,
+			found:         true,
+			validateDelay: true,
+			faultDelay:    100 * time.Millisecond,
+		},
+		{
+			name: "valid, 5m delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "5m",
+			),
+			expectErr:     false,
+			found:         true,
+			validateDelay: true,
+			faultDelay:    5 * time.Minute,
+		},
+		{
+			name: "invalid, negative delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "-1s",
+			),
+			expectErr:     true,
+			found:         true,
+			validateDelay: false,
+			faultDelay:    0,
+		},
+		{
+			name: "invalid, 6m delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "6m",
+			),
+			expectErr:     true,
+			found:         true,
+			validateDelay: false,
+			faultDelay:    0,
+		},
+		{
+			name: "invalid, 100 delay (no units)",
+			md: metadata.Pairs(
+				faultdelayHeader, "100",
+			),
+			expectErr:     true,
+			found:         true,
+			validateDelay: false,
+			faultDelay:    0,
+		},
+		{
+			name: "invalid, blah delay",
+			md: metadata.Pairs(
+				faultdelayHeader, "blah",
+			),
+			expectErr:     true,
+			found:         true,
+			validateDelay: false,
+			faultDelay:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, faultDelay, err := readFaultDelay(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.validateDelay {
+				if faultDelay != tt.faultDelay {
+					t.Errorf("test: %s,faultDelay:%v != tt.faultDelay:%v", tt.name, faultDelay, tt.faultDelay)
+				}
+			}
+		})
+	}
+
+}
+
+type delayTest struct {
+	name     string
+	md       metadata.MD
+	budget   Budget
+	dryRun   bool
+	requests int
+	code     codes.Code
+	delayed  int
+	handled  int
+	delays   uint64
+}
+
+// go test -run TestDelay -v
+func TestDelay(t *testing.T) {
+	tests := []delayTest{
+		{
+			name:     "delay only, then handled",
+			md:       metadata.Pairs(faultmodulusHeader, "1", faultdelayHeader, "50ms"),
+			requests: 1,
+			code:     codes.OK,
+			delayed:  1,
+			handled:  1,
+			delays:   1,
+		},
+		{
+			name:     "delay, then the fault code",
+			md:       metadata.Pairs(faultmodulusHeader, "1", faultdelayHeader, "50ms", faultcodesHeader, "14"),
+			requests: 1,
+			code:     codes.Unavailable,
+			delayed:  1,
+		},
+		{
+			name:     "not selected, no fault mode",
+			md:       metadata.Pairs(faultdelayHeader, "50ms"),
+			requests: 1,
+			code:     codes.OK,
+			handled:  1,
+		},
+		{
+			name:     "modulus 2, every second request is delayed",
+			md:       metadata.Pairs(faultmodulusHeader, "2", faultdelayHeader, "50ms"),
+			requests: 4,
+			code:     codes.OK,
+			delayed:  2,
+			handled:  4,
+			delays:   2,
+		},
+		{
+			name:     "budget max faults 1",
+			md:       metadata.Pairs(faultmodulusHeader, "1", faultdelayHeader, "50ms"),
+			budget:   Budget{MaxFaults: 1},
+			requests: 3,
+			code:     codes.OK,
+			delayed:  1,
+			handled:  3,
+			delays:   1,
+		},
+		{
+			name:     "dry run, no delay",
+			md:       metadata.Pairs(faultmodulusHeader, "1", faultdelayHeader, "50ms"),
+			dryRun:   true,
+			requests: 1,
+			code:     codes.OK,
+			handled:  1,
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var handled int
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled++
+				return req, nil
+			}
+
+			SetDryRun(tt.dryRun)
+			defer SetDryRun(false)
+
+			interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{Budget: tt.budget}, 0)
+
+			before := GetStats().Delays
+
+			var delayed int
+			for i := 0; i < tt.requests; i++ {
+				ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+				start := time.Now()
+				_, err := interceptor(ctx, "req", info, handler)
+				if time.Since(start) >= 50*time.Millisecond {
+					delayed++
+				}
+
+				if status.Code(err) != tt.code && status.Code(err) != codes.OK {
+					t.Errorf("test: %s, code:%s != tt.code:%s", tt.name, status.Code(err), tt.code)
+				}
+			}
+
+			if delayed != tt.delayed {
+				t.Errorf("test: %s, delayed:%d != tt.delayed:%d", tt.name, delayed, tt.delayed)
+			}
+			if handled != tt.handled {
+				t.Errorf("test: %s, handled:%d != tt.handled:%d", tt.name, handled, tt.handled)
+			}
+			if d := GetStats().Delays - before; d != tt.delays {
+				t.Errorf("test: %s, delays:%d != tt.delays:%d", tt.name, d, tt.delays)
+			}
+		})
+	}
+}
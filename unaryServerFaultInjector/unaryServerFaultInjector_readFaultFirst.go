@@ -0,0 +1,57 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strconv"
+
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultfirstHeader = "faultfirst"
+)
+
+// readFaultFirst reads the "faultfirst", including validation
+// first needs to be a integer between 1-1000000
+// e.g. faultfirst = 1 ( only the first request faults )
+// e.g. faultfirst = 3 ( requests 1,2,3 fault, then all succeed )
+// The counter is the "faultscope" counter, so use "faultscope: session" or "method", because the
+// default "global" counter only counts from the server start, so later requests never fault
+func readFaultFirst(md *metadata.MD, debugLevel int) (found bool, faultFirst uint64, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultFirstValue []string
+
+	if faultFirstValue, found = (*md)[faultfirstHeader]; found {
+
+		ff, err := strconv.ParseInt(faultFirstValue[0], 0, 64)
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultFirst ParseInt error")
+		}
+
+		var errV error
+		faultFirst, errV = validate.ValidateFirst(ff)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultFirst ValidateFirst error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultFirst faultFirst:%d", faultFirst)
+		}
+
+		return found, faultFirst, nil
+	}
+
+	// faultfirstHeader does not exist
+	return found, 0, nil
+}
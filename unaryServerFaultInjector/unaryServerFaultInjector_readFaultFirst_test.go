@@ -0,0 +1,122 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type readFaultFirstTest struct {
+	name          string
+	md            metadata.MD
+	expectErr     bool
+	found         bool
+	validateFirst bool
+	faultFirst    uint64
+}
+
+// go test -run TestReadFaultFirst -v
+func TestReadFaultFirst(t *testing.T) {
+	tests := []readFaultFirstTest{
+		{
+			name: "valid no fault first header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr:     false,
+			found:         false,
+			validateFirst: false,
+			faultFirst:    0,
+		},
+		{
+			name: "valid, 1 first",
+			md: metadata.Pairs(
+				faultfirstHeader, "1",
+			),
+			expectErr:     false,
+			found:         true,
+			validateFirst: true,
+			faultFirst:    1,
+		},
+		{
+			name: "valid, 3 first",
+			md: metadata.Pairs(
+				faultfirstHeader, "3",
+			),
+			expectErr:     false,
+			found:         true,
+			validateFirst: true,
+			faultFirst:    3,
+		},
+		{
+			name: "valid, 1000000 first",
+			md: metadata.Pairs(
+				faultfirstHeader, "1000000",
+			),
+			expectErr:     false,
+			found:         true,
+			validateFirst: true,
+			faultFirst:    1000000,
+		},
+		{
+			name: "invalid, zero first",
+			md: metadata.Pairs(
+				faultfirstHeader, "0",
+			),
+			expectErr:     true,
+			found:         true,
+			validateFirst: false,
+			faultFirst:    0,
+		},
+		{
+			name: "invalid, negative first",
+			md: metadata.Pairs(
+				faultfirstHeader, "-1",
+			),
+			expectErr:     true,
+			found:         true,
+			validateFirst: false,
+			faultFirst:    0,
+		},
+		{
+			name: "invalid, 1000001 first",
+			md: metadata.Pairs(
+				faultfirstHeader, "1000001",
+			),
+			expectErr:     true,
+			found:         true,
+			validateFirst: false,
+			faultFirst:    0,
+		},
+		{
+			name: "invalid, blah first",
+			md: metadata.Pairs(
+				faultfirstHeader, "blah",
+			),
+			expectErr:     true,
+			found:         true,
+			validateFirst: false,
+			faultFirst:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, faultFirst, err := readFaultFirst(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.validateFirst {
+				if faultFirst != tt.faultFirst {
+					t.Errorf("test: %s,faultFirst:%v != tt.faultFirst:%v", tt.name, faultFirst, tt.faultFirst)
+				}
+			}
+		})
+	}
+
+}
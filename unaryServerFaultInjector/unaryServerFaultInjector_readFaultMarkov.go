@@ -0,0 +1,47 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/markov"
+)
+
+const (
+	faultmarkovHeader = "faultmarkov"
+)
+
+// readFaultMarkov reads the "faultmarkov" two state ( Gilbert-Elliott ) model, including validation
+// the value is "good to bad, bad to good, good rate, bad rate", all parts-per-million 0-1000000
+// e.g. faultmarkov = 10000,200000,0,900000
+// ( 1% chance to start a burst, average burst of 5 requests, no faults when good, 90% faults when bad )
+func readFaultMarkov(md *metadata.MD, debugLevel int) (found bool, params markov.Params, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultMarkovValue []string
+
+	if faultMarkovValue, found = (*md)[faultmarkovHeader]; found {
+
+		var errP error
+		params, errP = markov.Parse(faultMarkovValue[0])
+		if errP != nil {
+			return found, params, status.Error(codes.InvalidArgument,
+				"readFaultMarkov Parse error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultMarkov params:%s", params)
+		}
+
+		return found, params, nil
+	}
+
+	// faultmarkovHeader does not exist
+	return found, params, nil
+}
@@ -0,0 +1,81 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/markov"
+)
+
+type readFaultMarkovTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	params    markov.Params
+}
+
+// go test -run TestReadFaultMarkov -v
+func TestReadFaultMarkov(t *testing.T) {
+	tests := []readFaultMarkovTest{
+		{
+			name: "valid no fault markov header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr: false,
+			found:     false,
+		},
+		{
+			name: "valid 10000,200000,0,900000",
+			md: metadata.Pairs(
+				faultmarkovHeader, "10000,200000,0,900000",
+			),
+			expectErr: false,
+			found:     true,
+			params: markov.Params{
+				GoodToBad: 10000,
+				BadToGood: 200000,
+				GoodPPM:   0,
+				BadPPM:    900000,
+			},
+		},
+		{
+			name: "invalid 3 values",
+			md: metadata.Pairs(
+				faultmarkovHeader, "10000,200000,0",
+			),
+			expectErr: true,
+			found:     true,
+		},
+		{
+			name: "invalid 1000001",
+			md: metadata.Pairs(
+				faultmarkovHeader, "10000,200000,0,1000001",
+			),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, params, err := readFaultMarkov(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.expectErr {
+				return
+			}
+			if params != tt.params {
+				t.Errorf("test: %s,params:%v != tt.params:%v", tt.name, params, tt.params)
+			}
+		})
+	}
+}
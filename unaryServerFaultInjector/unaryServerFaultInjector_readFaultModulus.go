@@ -1,3 +1,5 @@
+//go:build !nofaultinjection
+
 package unaryServerFaultInjector
 
 import (
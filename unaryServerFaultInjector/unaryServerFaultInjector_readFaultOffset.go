@@ -0,0 +1,56 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strconv"
+
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultoffsetHeader = "faultoffset"
+)
+
+// readFaultOffset reads the "faultoffset", including validation
+// offset needs to be a integer between 0-1000000
+// the offset is the request counter the "faultmodulus" or "faultfirst" pattern starts at
+// e.g. faultmodulus = 2, faultoffset = 1 ( requests 1,3,5... )
+// e.g. faultfirst = 2, faultoffset = 5 ( requests 5,6 )
+func readFaultOffset(md *metadata.MD, debugLevel int) (found bool, faultOffset uint64, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultOffsetValue []string
+
+	if faultOffsetValue, found = (*md)[faultoffsetHeader]; found {
+
+		fo, err := strconv.ParseInt(faultOffsetValue[0], 0, 64)
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultOffset ParseInt error")
+		}
+
+		var errV error
+		faultOffset, errV = validate.ValidateOffset(fo)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultOffset ValidateOffset error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultOffset faultOffset:%d", faultOffset)
+		}
+
+		return found, faultOffset, nil
+	}
+
+	// faultoffsetHeader does not exist
+	return found, 0, nil
+}
@@ -0,0 +1,112 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type readFaultOffsetTest struct {
+	name           string
+	md             metadata.MD
+	expectErr      bool
+	found          bool
+	validateOffset bool
+	faultOffset    uint64
+}
+
+// go test -run TestReadFaultOffset -v
+func TestReadFaultOffset(t *testing.T) {
+	tests := []readFaultOffsetTest{
+		{
+			name: "valid no fault offset header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr:      false,
+			found:          false,
+			validateOffset: false,
+			faultOffset:    0,
+		},
+		{
+			name: "valid, zero offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "0",
+			),
+			expectErr:      false,
+			found:          true,
+			validateOffset: true,
+			faultOffset:    0,
+		},
+		{
+			name: "valid, 1 offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "1",
+			),
+			expectErr:      false,
+			found:          true,
+			validateOffset: true,
+			faultOffset:    1,
+		},
+		{
+			name: "valid, 1000000 offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "1000000",
+			),
+			expectErr:      false,
+			found:          true,
+			validateOffset: true,
+			faultOffset:    1000000,
+		},
+		{
+			name: "invalid, negative offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "-1",
+			),
+			expectErr:      true,
+			found:          true,
+			validateOffset: false,
+			faultOffset:    0,
+		},
+		{
+			name: "invalid, 1000001 offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "1000001",
+			),
+			expectErr:      true,
+			found:          true,
+			validateOffset: false,
+			faultOffset:    0,
+		},
+		{
+			name: "invalid, blah offset",
+			md: metadata.Pairs(
+				faultoffsetHeader, "blah",
+			),
+			expectErr:      true,
+			found:          true,
+			validateOffset: false,
+			faultOffset:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, faultOffset, err := readFaultOffset(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.validateOffset {
+				if faultOffset != tt.faultOffset {
+					t.Errorf("test: %s,faultOffset:%v != tt.faultOffset:%v", tt.name, faultOffset, tt.faultOffset)
+				}
+			}
+		})
+	}
+
+}
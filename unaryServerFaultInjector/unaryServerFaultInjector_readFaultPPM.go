@@ -0,0 +1,56 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strconv"
+
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultppmHeader = "faultppm"
+)
+
+// readFaultPPM reads the "faultppm" parts-per-million, including validation
+// ppm needs to be a integer between 1-1000000
+// e.g. faultppm = 1 ( 0.0001% )
+// e.g. faultppm = 100 ( 0.01% )
+// e.g. faultppm = 10000 ( 1% )
+func readFaultPPM(md *metadata.MD, debugLevel int) (found bool, faultPPM int, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultPPMValue []string
+
+	if faultPPMValue, found = (*md)[faultppmHeader]; found {
+
+		fp, err := strconv.ParseInt(faultPPMValue[0], 0, 64)
+		if err != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultPPM ParseInt error")
+		}
+
+		var errV error
+		faultPPM, errV = validate.ValidatePPM(fp)
+		if errV != nil {
+			return found, 0, status.Error(codes.InvalidArgument,
+				"readFaultPPM ValidatePPM error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultPPM faultPPM:%d", faultPPM)
+		}
+
+		return found, faultPPM, nil
+	}
+
+	// faultppmHeader does not exist
+	return found, 0, nil
+}
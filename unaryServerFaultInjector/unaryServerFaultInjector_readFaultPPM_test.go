@@ -0,0 +1,127 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type testReadFaultPPM struct {
+	name        string
+	md          metadata.MD
+	expectErr   bool
+	found       bool
+	validatePPM bool
+	faultPPM    int
+}
+
+// go test -run TestReadFaultPPM -v
+func TestReadFaultPPM(t *testing.T) {
+	tests := []testReadFaultPPM{
+		{
+			name: "valid no fault ppm header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr:   false,
+			found:       false,
+			validatePPM: false,
+			faultPPM:    0,
+		},
+		{
+			name: "valid, 1 ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "1",
+			),
+			expectErr:   false,
+			found:       true,
+			validatePPM: true,
+			faultPPM:    1,
+		},
+		{
+			name: "valid, 100 ppm ( 0.01% )",
+			md: metadata.Pairs(
+				faultppmHeader, "100",
+			),
+			expectErr:   false,
+			found:       true,
+			validatePPM: true,
+			faultPPM:    100,
+		},
+		{
+			name: "valid, 1000000 ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "1000000",
+			),
+			expectErr:   false,
+			found:       true,
+			validatePPM: true,
+			faultPPM:    1000000,
+		},
+		{
+			name: "invalid, zero ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "0",
+			),
+			expectErr:   true,
+			found:       true,
+			validatePPM: false,
+		},
+		{
+			name: "invalid, negative ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "-10",
+			),
+			expectErr:   true,
+			found:       true,
+			validatePPM: false,
+		},
+		{
+			name: "invalid, 1000001 ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "1000001",
+			),
+			expectErr:   true,
+			found:       true,
+			validatePPM: false,
+		},
+		{
+			name: "invalid, 0.5 ppm (non integer)",
+			md: metadata.Pairs(
+				faultppmHeader, "0.5",
+			),
+			expectErr:   true,
+			found:       true,
+			validatePPM: false,
+		},
+		{
+			name: "invalid, blah ppm",
+			md: metadata.Pairs(
+				faultppmHeader, "blah",
+			),
+			expectErr:   true,
+			found:       true,
+			validatePPM: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, faultPPM, err := readFaultPPM(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.validatePPM {
+				if faultPPM != tt.faultPPM {
+					t.Errorf("test: %s,faultPPM:%d != tt.faultPPM:%d", tt.name, faultPPM, tt.faultPPM)
+				}
+			}
+		})
+	}
+
+}
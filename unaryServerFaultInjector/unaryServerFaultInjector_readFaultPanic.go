@@ -0,0 +1,58 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	faultpanicHeader = "faultpanic"
+
+	// defaultPanicValue is the panic value, when the "faultpanic" value is empty
+	defaultPanicValue = "fault injection panic"
+)
+
+// readFaultPanic reads the optional "faultpanic", which is the panic value
+// The selected request panics in the interceptor, instead of calling the handler
+// e.g. faultpanic = boom ( panic("boom") )
+// e.g. faultpanic = "" ( panic("fault injection panic") )
+func readFaultPanic(md *metadata.MD, debugLevel int) (found bool, value string) {
+
+	var faultPanicValue []string
+
+	if faultPanicValue, found = (*md)[faultpanicHeader]; found {
+
+		value = faultPanicValue[0]
+		if value == "" {
+			value = defaultPanicValue
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultPanic value:%q", value)
+		}
+
+		return found, value
+	}
+
+	// faultpanicHeader does not exist
+	return found, ""
+}
+
+// applyPanic panics with the value, instead of calling the handler, so the recovery
+// interceptor can be tested.  The fault injector must be chained inside the recovery
+// interceptor, or the panic isn't recovered, and the process exits
+func applyPanic(ctx context.Context, inj *injectedFault, debugLevel int) {
+
+	f := fault.Add(1)
+	p := panics.Add(1)
+
+	if debugLevel > 10 {
+		logger.Printf("applyPanic value:%q peer:%s counter:%d fault:%d panics:%d",
+			inj.panicValue, peerAddress(ctx), inj.counter, f, p)
+	}
+
+	panic(inj.panicValue)
+}
@@ -0,0 +1,148 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type readFaultPanicTest struct {
+	name  string
+	md    metadata.MD
+	found bool
+	value string
+}
+
+// go test -run TestReadFaultPanic -v
+func TestReadFaultPanic(t *testing.T) {
+	tests := []readFaultPanicTest{
+		{
+			name:  "valid no fault panic header",
+			md:    metadata.Pairs("anotherHeader", "doesn_t_matter"),
+			found: false,
+		},
+		{
+			name:  "valid, boom",
+			md:    metadata.Pairs(faultpanicHeader, "boom"),
+			found: true,
+			value: "boom",
+		},
+		{
+			name:  "valid, empty is the default value",
+			md:    metadata.Pairs(faultpanicHeader, ""),
+			found: true,
+			value: defaultPanicValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, value := readFaultPanic(&tt.md, 0)
+			if found != tt.found {
+				t.Errorf("test: %s, found:%t != tt.found:%t", tt.name, found, tt.found)
+			}
+			if value != tt.value {
+				t.Errorf("test: %s, value:%q != tt.value:%q", tt.name, value, tt.value)
+			}
+		})
+	}
+}
+
+// recovery is a minimal recovery interceptor, which the fault injector is chained inside
+func recovery(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "panic: %v", r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+type panicTest struct {
+	name    string
+	md      metadata.MD
+	code    codes.Code
+	message string
+	handled bool
+	panics  uint64
+	dryRun  bool
+}
+
+// go test -run TestPanic -v
+func TestPanic(t *testing.T) {
+	tests := []panicTest{
+		{
+			name:    "panic, recovered as Internal",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultpanicHeader, "boom"),
+			code:    codes.Internal,
+			message: "panic: boom",
+			panics:  1,
+		},
+		{
+			name:    "panic default value",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultpanicHeader, ""),
+			code:    codes.Internal,
+			message: "panic: " + defaultPanicValue,
+			panics:  1,
+		},
+		{
+			name:    "dry run, no panic",
+			md:      metadata.Pairs(faultmodulusHeader, "1", faultpanicHeader, "boom"),
+			code:    codes.OK,
+			handled: true,
+			dryRun:  true,
+		},
+		{
+			name:    "not selected, no fault mode",
+			md:      metadata.Pairs(faultpanicHeader, "boom"),
+			code:    codes.OK,
+			handled: true,
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var handled bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled = true
+				return req, nil
+			}
+
+			SetDryRun(tt.dryRun)
+			defer SetDryRun(false)
+
+			interceptor := UnaryServerFaultInjector(0)
+			chained := func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, handler)
+			}
+
+			before := GetStats().Panics
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := recovery(ctx, "req", info, chained)
+
+			s, _ := status.FromError(err)
+			if s.Code() != tt.code {
+				t.Errorf("test: %s, code:%s != tt.code:%s", tt.name, s.Code(), tt.code)
+			}
+			if tt.message != "" && s.Message() != tt.message {
+				t.Errorf("test: %s, message:%q != tt.message:%q", tt.name, s.Message(), tt.message)
+			}
+			if handled != tt.handled {
+				t.Errorf("test: %s, handled:%t != tt.handled:%t", tt.name, handled, tt.handled)
+			}
+			if p := GetStats().Panics - before; p != tt.panics {
+				t.Errorf("test: %s, panics:%d != tt.panics:%d", tt.name, p, tt.panics)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+)
+
+const (
+	faultrampHeader = "faultramp"
+)
+
+// readFaultRamp reads the "faultramp" profile, including validation
+// the value is "from,to,duration[,shape[,end]]", with from and to in percent 0-100
+// shape is "linear" or "stepN", and end is "hold" or "fall"
+// e.g. faultramp = 0,50,5m ( 0% rising linearly to 50% over 5 minutes, then 50% )
+// e.g. faultramp = 10,100,10m,step10,fall
+func readFaultRamp(md *metadata.MD, debugLevel int) (found bool, raw string, profile ramp.Profile, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultRampValue []string
+
+	if faultRampValue, found = (*md)[faultrampHeader]; found {
+
+		raw = faultRampValue[0]
+
+		var errP error
+		profile, errP = ramp.Parse(raw)
+		if errP != nil {
+			return found, raw, profile, status.Error(codes.InvalidArgument,
+				"readFaultRamp Parse error")
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultRamp ramp:%s", raw)
+		}
+
+		return found, raw, profile, nil
+	}
+
+	// faultrampHeader does not exist
+	return found, raw, profile, nil
+}
@@ -0,0 +1,96 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+)
+
+type readFaultRampTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	profile   ramp.Profile
+}
+
+// go test -run TestReadFaultRamp -v
+func TestReadFaultRamp(t *testing.T) {
+	tests := []readFaultRampTest{
+		{
+			name: "valid no fault ramp header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr: false,
+			found:     false,
+		},
+		{
+			name: "valid 0,50,5m",
+			md: metadata.Pairs(
+				faultrampHeader, "0,50,5m",
+			),
+			expectErr: false,
+			found:     true,
+			profile: ramp.Profile{
+				FromPPM:  0,
+				ToPPM:    500000,
+				Duration: 5 * time.Minute,
+			},
+		},
+		{
+			name: "valid 10,100,10m,step10,fall",
+			md: metadata.Pairs(
+				faultrampHeader, "10,100,10m,step10,fall",
+			),
+			expectErr: false,
+			found:     true,
+			profile: ramp.Profile{
+				FromPPM:  100000,
+				ToPPM:    1000000,
+				Duration: 10 * time.Minute,
+				Steps:    10,
+				Fall:     true,
+			},
+		},
+		{
+			name: "invalid to 101",
+			md: metadata.Pairs(
+				faultrampHeader, "0,101,5m",
+			),
+			expectErr: true,
+			found:     true,
+		},
+		{
+			name: "invalid duration",
+			md: metadata.Pairs(
+				faultrampHeader, "0,50,blah",
+			),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, _, profile, err := readFaultRamp(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.expectErr {
+				return
+			}
+			if profile != tt.profile {
+				t.Errorf("test: %s,profile:%+v != tt.profile:%+v", tt.name, profile, tt.profile)
+			}
+		})
+	}
+}
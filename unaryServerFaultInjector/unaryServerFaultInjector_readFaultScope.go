@@ -0,0 +1,45 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/validate"
+)
+
+const (
+	faultscopeHeader = "faultscope"
+)
+
+// readFaultScope reads the optional "faultscope", including validation
+// e.g. faultscope = global ( one counter for the whole server )
+// e.g. faultscope = method ( a counter per GRPC method )
+// e.g. faultscope = peer ( a counter per client address )
+// e.g. faultscope = identity ( a counter per mTLS client identity )
+// e.g. faultscope = session ( a counter per "faultsession" header )
+func readFaultScope(md *metadata.MD, debugLevel int) (found bool, scope Scope, err error) {
+
+	var faultScopeValue []string
+
+	if faultScopeValue, found = (*md)[faultscopeHeader]; found {
+
+		s, errV := validate.ValidateScope(faultScopeValue[0])
+		if errV != nil {
+			return found, scope, status.Error(codes.InvalidArgument,
+				"readFaultScope ValidateScope error")
+		}
+		scope = StringToScope(s)
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultScope scope:%s", scope)
+		}
+
+		return found, scope, nil
+	}
+
+	// faultscopeHeader does not exist
+	return found, scope, nil
+}
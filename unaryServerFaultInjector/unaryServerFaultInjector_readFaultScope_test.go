@@ -0,0 +1,82 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type readFaultScopeTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	scope     Scope
+}
+
+// go test -run TestReadFaultScope -v
+func TestReadFaultScope(t *testing.T) {
+	tests := []readFaultScopeTest{
+		{
+			name: "valid no fault scope header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr: false,
+			found:     false,
+			scope:     ScopeGlobal,
+		},
+		{
+			name: "valid global",
+			md: metadata.Pairs(
+				faultscopeHeader, "global",
+			),
+			expectErr: false,
+			found:     true,
+			scope:     ScopeGlobal,
+		},
+		{
+			name: "valid method",
+			md: metadata.Pairs(
+				faultscopeHeader, "method",
+			),
+			expectErr: false,
+			found:     true,
+			scope:     ScopeMethod,
+		},
+		{
+			name: "valid Session",
+			md: metadata.Pairs(
+				faultscopeHeader, "Session",
+			),
+			expectErr: false,
+			found:     true,
+			scope:     ScopeSession,
+		},
+		{
+			name: "invalid blah",
+			md: metadata.Pairs(
+				faultscopeHeader, "blah",
+			),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, scope, err := readFaultScope(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if !tt.expectErr && scope != tt.scope {
+				t.Errorf("test: %s,scope:%s != tt.scope:%s", tt.name, scope, tt.scope)
+			}
+		})
+	}
+}
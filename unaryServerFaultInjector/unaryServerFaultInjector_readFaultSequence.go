@@ -0,0 +1,62 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strconv"
+
+	_ "unsafe"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/sequence"
+)
+
+const (
+	faultsequenceHeader = "faultsequence"
+	faultrepeatHeader   = "faultrepeat"
+)
+
+// readFaultSequence reads the "faultsequence" script, and the optional "faultrepeat"
+// the script is a comma seperated list of "ok" or GRPC status codes
+// e.g. faultsequence = ok,14,14,ok,4,ok
+// e.g. faultsequence = 14,ok and faultrepeat = true ( every other request faults )
+// without "faultrepeat", all requests after the end of the sequence succeed
+func readFaultSequence(md *metadata.MD, debugLevel int) (found bool, script string, steps []sequence.Step, repeat bool, err error) {
+
+	// metadata keys are always lower case
+	// https://github.com/grpc/grpc-go/blob/v1.68.0/metadata/metadata.go#L207
+	var faultSequenceValue []string
+
+	if faultSequenceValue, found = (*md)[faultsequenceHeader]; found {
+
+		script = faultSequenceValue[0]
+
+		var errP error
+		steps, errP = sequence.Parse(script)
+		if errP != nil {
+			return found, script, steps, repeat, status.Error(codes.InvalidArgument,
+				"readFaultSequence Parse error")
+		}
+
+		if faultRepeatValue, foundR := (*md)[faultrepeatHeader]; foundR {
+			var errR error
+			repeat, errR = strconv.ParseBool(faultRepeatValue[0])
+			if errR != nil {
+				return found, script, steps, repeat, status.Error(codes.InvalidArgument,
+					"readFaultSequence faultrepeat ParseBool error")
+			}
+		}
+
+		if debugLevel > 10 {
+			logger.Printf("readFaultSequence script:%s repeat:%t", script, repeat)
+		}
+
+		return found, script, steps, repeat, nil
+	}
+
+	// faultsequenceHeader does not exist
+	return found, script, steps, repeat, nil
+}
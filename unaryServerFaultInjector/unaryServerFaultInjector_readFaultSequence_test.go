@@ -0,0 +1,99 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type readFaultSequenceTest struct {
+	name      string
+	md        metadata.MD
+	expectErr bool
+	found     bool
+	steps     int
+	repeat    bool
+}
+
+// go test -run TestReadFaultSequence -v
+func TestReadFaultSequence(t *testing.T) {
+	tests := []readFaultSequenceTest{
+		{
+			name: "valid no fault sequence header",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			expectErr: false,
+			found:     false,
+		},
+		{
+			name: "valid ok,14,14,ok,4,ok",
+			md: metadata.Pairs(
+				faultsequenceHeader, "ok,14,14,ok,4,ok",
+			),
+			expectErr: false,
+			found:     true,
+			steps:     6,
+			repeat:    false,
+		},
+		{
+			name: "valid 14,ok repeat",
+			md: metadata.Pairs(
+				faultsequenceHeader, "14,ok",
+				faultrepeatHeader, "true",
+			),
+			expectErr: false,
+			found:     true,
+			steps:     2,
+			repeat:    true,
+		},
+		{
+			name: "valid repeat without sequence is ignored",
+			md: metadata.Pairs(
+				faultrepeatHeader, "true",
+			),
+			expectErr: false,
+			found:     false,
+		},
+		{
+			name: "invalid sequence code 17",
+			md: metadata.Pairs(
+				faultsequenceHeader, "ok,17",
+			),
+			expectErr: true,
+			found:     true,
+		},
+		{
+			name: "invalid repeat blah",
+			md: metadata.Pairs(
+				faultsequenceHeader, "ok,14",
+				faultrepeatHeader, "blah",
+			),
+			expectErr: true,
+			found:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, _, steps, repeat, err := readFaultSequence(&tt.md, 0)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("test: %s, expected error: %v, got: %v", tt.name, tt.expectErr, err != nil)
+			}
+			if found != tt.found {
+				t.Errorf("test: %s,found:%t != tt.found%t", tt.name, found, tt.found)
+			}
+			if tt.expectErr {
+				return
+			}
+			if len(steps) != tt.steps {
+				t.Errorf("test: %s,len(steps):%d != tt.steps:%d", tt.name, len(steps), tt.steps)
+			}
+			if repeat != tt.repeat {
+				t.Errorf("test: %s,repeat:%t != tt.repeat:%t", tt.name, repeat, tt.repeat)
+			}
+		})
+	}
+}
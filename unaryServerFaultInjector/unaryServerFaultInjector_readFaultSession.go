@@ -0,0 +1,32 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	faultsessionHeader = "faultsession"
+
+	// maxSessionLength limits the memory used by a session id
+	maxSessionLength = 128
+)
+
+// readFaultSession reads the optional "faultsession" id, which a test client
+// can use to stop its sequence being shared with other test clients
+// session ids longer than maxSessionLength are truncated
+func readFaultSession(md *metadata.MD) (session string) {
+
+	faultSessionValue, found := (*md)[faultsessionHeader]
+	if !found {
+		return session
+	}
+
+	session = faultSessionValue[0]
+	if len(session) > maxSessionLength {
+		session = session[:maxSessionLength]
+	}
+
+	return session
+}
@@ -0,0 +1,52 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type readFaultSessionTest struct {
+	name    string
+	md      metadata.MD
+	session string
+}
+
+// go test -run TestReadFaultSession -v
+func TestReadFaultSession(t *testing.T) {
+	tests := []readFaultSessionTest{
+		{
+			name: "no session",
+			md: metadata.Pairs(
+				"anotherHeader", "doesn_t_matter",
+			),
+			session: "",
+		},
+		{
+			name: "session test1",
+			md: metadata.Pairs(
+				faultsessionHeader, "test1",
+			),
+			session: "test1",
+		},
+		{
+			name: "session truncated",
+			md: metadata.Pairs(
+				faultsessionHeader, strings.Repeat("a", maxSessionLength+10),
+			),
+			session: strings.Repeat("a", maxSessionLength),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := readFaultSession(&tt.md)
+			if session != tt.session {
+				t.Errorf("test: %s,session:%s != tt.session:%s", tt.name, session, tt.session)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// scopeKey returns the counter key for the scope
+// the key is prefixed with the scope, so the scopes can share the same counters
+// ScopeGlobal returns "", and the global counter is used
+func scopeKey(ctx context.Context, info *grpc.UnaryServerInfo, md *metadata.MD, scope Scope) string {
+
+	switch scope {
+	case ScopeMethod:
+		return "method|" + info.FullMethod
+	case ScopePeer:
+		return "peer|" + peerAddress(ctx)
+	case ScopeIdentity:
+		return "identity|" + peerIdentity(ctx)
+	case ScopeSession:
+		return "session|" + readFaultSession(md)
+	}
+
+	return ""
+}
+
+// peerAddress returns the client address, e.g. "127.0.0.1:54321"
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+// peerIdentity returns the client mTLS certificate identity, which is the
+// first URI SAN ( e.g. SPIFFE ), or the first DNS SAN, or the subject common name
+// Clients without a certificate are identified by peer address
+func peerIdentity(ctx context.Context) string {
+
+	if id, ok := certIdentity(ctx); ok {
+		return id
+	}
+
+	return peerAddress(ctx)
+}
+
+// callerKey identifies the caller for the per caller fault budget
+// the mTLS identity, or the client IP address, without the port, so new connections are the same caller
+func callerKey(ctx context.Context) string {
+
+	if id, ok := certIdentity(ctx); ok {
+		return id
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+
+	return p.Addr.String()
+}
+
+// certIdentity returns the client mTLS certificate identity, if there is one
+func certIdentity(ctx context.Context) (string, bool) {
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		cert := tlsInfo.State.PeerCertificates[0]
+		switch {
+		case len(cert.URIs) > 0:
+			return cert.URIs[0].String(), true
+		case len(cert.DNSNames) > 0:
+			return cert.DNSNames[0], true
+		case cert.Subject.CommonName != "":
+			return cert.Subject.CommonName, true
+		}
+	}
+
+	return "", false
+}
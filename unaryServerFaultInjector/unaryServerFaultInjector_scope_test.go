@@ -0,0 +1,111 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type scopeKeyTest struct {
+	name  string
+	peer  *peer.Peer
+	md    metadata.MD
+	scope Scope
+	key   string
+}
+
+func tlsPeer(cert *x509.Certificate) *peer.Peer {
+	return &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 54321},
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			},
+		},
+	}
+}
+
+// go test -run TestScopeKey -v
+func TestScopeKey(t *testing.T) {
+
+	addrPeer := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 54321},
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.org/test")
+
+	tests := []scopeKeyTest{
+		{
+			name:  "global",
+			peer:  addrPeer,
+			scope: ScopeGlobal,
+			key:   "",
+		},
+		{
+			name:  "method",
+			peer:  addrPeer,
+			scope: ScopeMethod,
+			key:   "method|/grpc.examples.echo.Echo/UnaryEcho",
+		},
+		{
+			name:  "peer",
+			peer:  addrPeer,
+			scope: ScopePeer,
+			key:   "peer|127.0.0.1:54321",
+		},
+		{
+			name:  "identity without tls is the peer address",
+			peer:  addrPeer,
+			scope: ScopeIdentity,
+			key:   "identity|127.0.0.1:54321",
+		},
+		{
+			name:  "identity uri san",
+			peer:  tlsPeer(&x509.Certificate{URIs: []*url.URL{spiffe}, DNSNames: []string{"client.example.org"}}),
+			scope: ScopeIdentity,
+			key:   "identity|spiffe://example.org/test",
+		},
+		{
+			name:  "identity dns san",
+			peer:  tlsPeer(&x509.Certificate{DNSNames: []string{"client.example.org"}}),
+			scope: ScopeIdentity,
+			key:   "identity|client.example.org",
+		},
+		{
+			name:  "identity common name",
+			peer:  tlsPeer(&x509.Certificate{Subject: pkix.Name{CommonName: "client"}}),
+			scope: ScopeIdentity,
+			key:   "identity|client",
+		},
+		{
+			name:  "session",
+			peer:  addrPeer,
+			md:    metadata.Pairs(faultsessionHeader, "test1"),
+			scope: ScopeSession,
+			key:   "session|test1",
+		},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), tt.peer)
+			key := scopeKey(ctx, info, &tt.md, tt.scope)
+			if key != tt.key {
+				t.Errorf("test: %s,key:%s != tt.key:%s", tt.name, key, tt.key)
+			}
+		})
+	}
+}
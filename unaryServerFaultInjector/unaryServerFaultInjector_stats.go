@@ -0,0 +1,99 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/budget"
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+)
+
+var (
+	// rampPPM is the most recent "faultramp" fault rate
+	rampPPM atomic.Int64
+
+	// budgetTotal, budgetRate and budgetCaller count the faults skipped by the Budget
+	budgetTotal  atomic.Uint64
+	budgetRate   atomic.Uint64
+	budgetCaller atomic.Uint64
+
+	// untrusted counts the requests with untrusted fault headers
+	untrusted atomic.Uint64
+
+	// delays counts the requests only delayed, by "faultdelay" or a scenario rule delay
+	delays atomic.Uint64
+
+	// connections counts the "faultconnection" closes and drains
+	connections atomic.Uint64
+
+	// corrupted counts the "faultcorrupt" responses
+	corrupted atomic.Uint64
+
+	// empty counts the fault code zero (0) zero valued responses
+	empty atomic.Uint64
+
+	// panics counts the "faultpanic" panics
+	panics atomic.Uint64
+
+	// cancels counts the "faultcancel" cancelled handlers, not the handlers which returned before
+	// the cancel, and cancelIgnored the handlers
+	// which were still running after the CancelGrace
+	cancels       atomic.Uint64
+	cancelIgnored atomic.Uint64
+)
+
+// GetStats returns a snapshot of the counters
+func GetStats() Stats {
+	return Stats{
+		Requests:      count.Load(),
+		Success:       success.Load(),
+		Faults:        fault.Load(),
+		RampPPM:       rampPPM.Load(),
+		BudgetTotal:   budgetTotal.Load(),
+		BudgetRate:    budgetRate.Load(),
+		BudgetCaller:  budgetCaller.Load(),
+		Disabled:      disabled.Load(),
+		DryRun:        dryRun.Load(),
+		Untrusted:     untrusted.Load(),
+		Delays:        delays.Load(),
+		Connections:   connections.Load(),
+		Corrupted:     corrupted.Load(),
+		Empty:         empty.Load(),
+		Panics:        panics.Load(),
+		Cancels:       cancels.Load(),
+		CancelIgnored: cancelIgnored.Load(),
+	}
+}
+
+// budgetExhausted counts the skipped fault
+// The first time each limit is reached is always logged, so the exhaustion is visible
+func budgetExhausted(reason budget.Reason, debugLevel int) {
+
+	var c uint64
+	switch reason {
+	case budget.Total:
+		c = budgetTotal.Add(1)
+	case budget.Rate:
+		c = budgetRate.Add(1)
+	case budget.Caller:
+		c = budgetCaller.Add(1)
+	}
+
+	if c == 1 || debugLevel > 10 {
+		logger.Printf("fault budget exhausted reason:%s skipped:%d", reason, c)
+	}
+}
+
+// rampRate returns the ramp fault rate, and records it in the stats
+// the ramp starts on the first request, so the start is set with CompareAndSwap
+func rampRate(start *atomic.Uint64, profile ramp.Profile, now time.Time) int {
+
+	start.CompareAndSwap(0, uint64(now.UnixNano()))
+
+	ppm := profile.PPM(now.Sub(time.Unix(0, int64(start.Load()))))
+	rampPPM.Store(int64(ppm))
+
+	return ppm
+}
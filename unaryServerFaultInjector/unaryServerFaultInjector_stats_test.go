@@ -0,0 +1,144 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/ramp"
+)
+
+type rampRateTest struct {
+	name    string
+	elapsed time.Duration
+	ppm     int
+}
+
+// go test -run TestRampRate -v
+func TestRampRate(t *testing.T) {
+
+	profile, err := ramp.Parse("0,50,10m,linear,fall")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begin := time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)
+
+	// the tests share the start, which is set by the first request
+	var start atomic.Uint64
+
+	tests := []rampRateTest{
+		{name: "first request", elapsed: 0, ppm: 0},
+		{name: "rising", elapsed: 2 * time.Minute, ppm: 100000},
+		{name: "top", elapsed: 10 * time.Minute, ppm: 500000},
+		{name: "falling", elapsed: 18 * time.Minute, ppm: 100000},
+		{name: "bottom", elapsed: time.Hour, ppm: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ppm := rampRate(&start, profile, begin.Add(tt.elapsed))
+			if ppm != tt.ppm {
+				t.Errorf("test: %s, ppm:%d != tt.ppm:%d", tt.name, ppm, tt.ppm)
+			}
+			if s := GetStats(); s.RampPPM != int64(tt.ppm) {
+				t.Errorf("test: %s, GetStats().RampPPM:%d != tt.ppm:%d", tt.name, s.RampPPM, tt.ppm)
+			}
+		})
+	}
+}
+
+type budgetTest struct {
+	name    string
+	budget  Budget
+	addrs   []string
+	faults  int
+	skipped Stats
+}
+
+// go test -run TestBudget -v
+func TestBudget(t *testing.T) {
+	tests := []budgetTest{
+		{
+			name:    "max faults 2",
+			budget:  Budget{MaxFaults: 2},
+			addrs:   []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.2:1000", "192.0.2.3:1000"},
+			faults:  2,
+			skipped: Stats{BudgetTotal: 2},
+		},
+		{
+			name:    "1 per second, burst 1",
+			budget:  Budget{FaultsPerSecond: 1, Burst: 1},
+			addrs:   []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.2:1000"},
+			faults:  1,
+			skipped: Stats{BudgetRate: 2},
+		},
+		{
+			name:    "1 per caller, the port is ignored",
+			budget:  Budget{MaxFaultsPerCaller: 1},
+			addrs:   []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.2:1000", "192.0.2.2:1001"},
+			faults:  2,
+			skipped: Stats{BudgetCaller: 2},
+		},
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{Budget: tt.budget}, 0)
+
+			before := GetStats()
+
+			var faults int
+			for _, addr := range tt.addrs {
+				ctx := metadata.NewIncomingContext(context.Background(),
+					metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14"))
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: netAddr(t, addr)})
+
+				if _, err := interceptor(ctx, "req", info, handler); err != nil {
+					if status.Code(err) != codes.Unavailable {
+						t.Fatalf("test: %s, unexpected error:%v", tt.name, err)
+					}
+					faults++
+				}
+			}
+
+			if faults != tt.faults {
+				t.Errorf("test: %s, faults:%d != tt.faults:%d", tt.name, faults, tt.faults)
+			}
+
+			after := GetStats()
+			skipped := Stats{
+				BudgetTotal:  after.BudgetTotal - before.BudgetTotal,
+				BudgetRate:   after.BudgetRate - before.BudgetRate,
+				BudgetCaller: after.BudgetCaller - before.BudgetCaller,
+			}
+			if skipped != tt.skipped {
+				t.Errorf("test: %s, skipped:%+v != tt.skipped:%+v", tt.name, skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func netAddr(t *testing.T, addr string) net.Addr {
+	a, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
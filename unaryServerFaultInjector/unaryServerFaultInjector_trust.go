@@ -0,0 +1,85 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
+)
+
+var (
+	errUntrustedPeer     = errors.New("peer not in the trusted peers")
+	errUntrustedIdentity = errors.New("identity not in the trusted identities")
+)
+
+// enabled is true if any check is configured
+func (t *Trust) enabled() bool {
+	return len(t.Peers) > 0 || len(t.Identities) > 0 || len(t.Secret) > 0
+}
+
+// check returns an error if the caller is not trusted to send the fault headers
+// method is the full gRPC method, which is bound into the signature
+func (t *Trust) check(ctx context.Context, md metadata.MD, method string, now time.Time) error {
+
+	if len(t.Peers) > 0 && !t.trustedPeer(ctx) {
+		return errUntrustedPeer
+	}
+
+	if len(t.Identities) > 0 {
+		id, ok := certIdentity(ctx)
+		if !ok || !slices.Contains(t.Identities, id) {
+			return errUntrustedIdentity
+		}
+	}
+
+	if len(t.Secret) > 0 {
+		if err := signature.Verify(md, method, t.Secret, t.MaxAge, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Trust) trustedPeer(ctx context.Context) bool {
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+
+	ap, err := netip.ParseAddrPort(p.Addr.String())
+	if err != nil {
+		return false
+	}
+
+	addr := ap.Addr().Unmap()
+	for _, prefix := range t.Peers {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripFaultHeaders returns a copy of the metadata, without the fault headers
+func stripFaultHeaders(md metadata.MD) metadata.MD {
+
+	stripped := make(metadata.MD, len(md))
+	for k, v := range md {
+		if !signature.IsFaultHeader(k) {
+			stripped[k] = v
+		}
+	}
+
+	return stripped
+}
@@ -0,0 +1,134 @@
+//go:build !nofaultinjection
+
+package unaryServerFaultInjector
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/randomizedcoder/grpcFaultInjection/internal/signature"
+)
+
+type trustTest struct {
+	name      string
+	trust     Trust
+	addr      string
+	signed    []byte
+	method    string
+	code      codes.Code
+	untrusted uint64
+}
+
+// go test -run TestTrust -v
+func TestTrust(t *testing.T) {
+	secret := []byte("secret")
+	peers := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+
+	tests := []trustTest{
+		{
+			name: "no trust, fault",
+			addr: "198.51.100.1:1000",
+			code: codes.Unavailable,
+		},
+		{
+			name:  "trusted peer, fault",
+			trust: Trust{Peers: peers},
+			addr:  "192.0.2.1:1000",
+			code:  codes.Unavailable,
+		},
+		{
+			name:      "untrusted peer, ignored",
+			trust:     Trust{Peers: peers},
+			addr:      "198.51.100.1:1000",
+			code:      codes.OK,
+			untrusted: 1,
+		},
+		{
+			name:      "untrusted peer, reject",
+			trust:     Trust{Peers: peers, Reject: true},
+			addr:      "198.51.100.1:1000",
+			code:      codes.PermissionDenied,
+			untrusted: 1,
+		},
+		{
+			name:   "signed, fault",
+			trust:  Trust{Secret: secret},
+			addr:   "198.51.100.1:1000",
+			signed: secret,
+			code:   codes.Unavailable,
+		},
+		{
+			name:      "signed for another method, ignored",
+			trust:     Trust{Secret: secret},
+			addr:      "198.51.100.1:1000",
+			signed:    secret,
+			method:    "/grpc.examples.echo.Echo/ServerStreamingEcho",
+			code:      codes.OK,
+			untrusted: 1,
+		},
+		{
+			name:      "wrong secret, ignored",
+			trust:     Trust{Secret: secret},
+			addr:      "198.51.100.1:1000",
+			signed:    []byte("wrong"),
+			code:      codes.OK,
+			untrusted: 1,
+		},
+		{
+			name:      "not signed, reject",
+			trust:     Trust{Secret: secret, Reject: true},
+			addr:      "192.0.2.1:1000",
+			code:      codes.PermissionDenied,
+			untrusted: 1,
+		},
+		{
+			name:      "identity without certificate, ignored",
+			trust:     Trust{Identities: []string{"spiffe://example.org/client"}},
+			addr:      "192.0.2.1:1000",
+			code:      codes.OK,
+			untrusted: 1,
+		},
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.examples.echo.Echo/UnaryEcho"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			interceptor := UnaryServerFaultInjectorWithConfig(UnaryServerInterceptorConfig{Trust: tt.trust}, 0)
+
+			md := metadata.Pairs(faultmodulusHeader, "1", faultcodesHeader, "14")
+			if tt.signed != nil {
+				method := info.FullMethod
+				if tt.method != "" {
+					method = tt.method
+				}
+				signature.Sign(md, method, tt.signed, time.Minute, time.Now())
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: netAddr(t, tt.addr)})
+
+			before := GetStats()
+
+			_, err := interceptor(ctx, "req", info, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("test: %s, code:%s != tt.code:%s", tt.name, code, tt.code)
+			}
+
+			if u := GetStats().Untrusted - before.Untrusted; u != tt.untrusted {
+				t.Errorf("test: %s, untrusted:%d != tt.untrusted:%d", tt.name, u, tt.untrusted)
+			}
+		})
+	}
+}